@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// component is the part of an aggregate report that covers the changelog of
+// a single project.
+type component struct {
+	name     string
+	config   *config
+	log      *changelog
+	releases releases
+}
+
+// aggregate collects the releases of all projects, except for their Unreleased
+// sections, as narrowed down by filter. Projects without such releases are
+// omitted.
+func aggregate(cfg *config, filter *releaseFilter) ([]component, error) {
+	names := cfg.projectNames()
+	if len(names) == 0 {
+		return nil, warn("No projects configured.")
+	}
+	var comps []component
+	for _, name := range names {
+		pcfg, err := cfg.project(name)
+		if err != nil {
+			return nil, err
+		}
+		log, err := parseChangelog(pcfg.changelog, pcfg)
+		if err != nil {
+			return nil, err
+		}
+		rs := filter.apply(log.releases.filter(func(r *release) bool {
+			return !r.unreleased()
+		}))
+		if len(rs) == 0 {
+			continue
+		}
+		comps = append(comps, component{
+			name:     name,
+			config:   pcfg,
+			log:      log,
+			releases: rs,
+		})
+	}
+	return comps, nil
+}
+
+// summary returns a release named after the component, which holds the
+// changes of all of its releases, grouped by label.
+func (c component) summary() *release {
+	sum := &release{version: c.name}
+	vers := make([]string, len(c.releases))
+	for i, rel := range c.releases {
+		vers[i] = rel.version
+		if !rel.date.IsZero() {
+			vers[i] += fmt.Sprintf(" (%s)", rel.date.Format(iso8601))
+		}
+		for _, g := range rel.changes {
+			for _, ch := range g.changes {
+				sum.pushChange(g.label, ch)
+			}
+		}
+	}
+	sum.note = fmt.Sprintf("%s: %s.", pluralize("Release", len(vers)), strings.Join(vers, ", "))
+	return sum
+}
+
+func (c component) doc() componentDoc {
+	r := newChangelogRenderer(c.log.path, c.config, c.log)
+	return componentDoc{
+		Name:      c.name,
+		Changelog: c.log.path,
+		Releases:  releaseSummaryDocs(c.releases),
+		Changes:   r.changesDocs(c.summary().changes),
+	}
+}
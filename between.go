@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// between returns the releases that succeed from and precede or equal to, in
+// the order in which they appear in the changelog.
+func (l *changelog) between(from, to string) (releases, error) {
+	scheme := l.versioning()
+	for _, ver := range []string{from, to} {
+		if !scheme.valid(ver) {
+			return nil, fmt.Errorf("invalid version: %s", ver)
+		}
+	}
+	if scheme.compare(from, to) >= 0 {
+		return nil, fmt.Errorf("%s must precede %s", from, to)
+	}
+	r := versionRange{{{">", from}, {"<=", to}}}
+	return l.releases.filter(func(rel *release) bool {
+		return r.match(scheme, rel.version)
+	}), nil
+}
+
+// mergeReleases merges rs into a single release named ver, whose changes are
+// grouped by label. Identical changes are only included once. If annotate is
+// set, each change is suffixed by the versions of the releases it came from.
+func mergeReleases(ver string, rs releases, annotate bool) *release {
+	type entry struct{ label, change string }
+	var (
+		merged  = &release{version: ver}
+		seen    = make(map[entry][]string) // the versions of each change
+		entries []entry
+	)
+	for _, rel := range rs {
+		if rel.note != "" {
+			merged.merge(&release{note: rel.note})
+		}
+		for _, g := range rel.changes {
+			for _, ch := range g.changes {
+				e := entry{g.label, ch}
+				if _, ok := seen[e]; !ok {
+					entries = append(entries, e)
+				}
+				seen[e] = append(seen[e], rel.version)
+			}
+		}
+	}
+	for _, e := range entries {
+		ch := e.change
+		if annotate {
+			ch = fmt.Sprintf("%s (%s)", ch, strings.Join(seen[e], ", "))
+		}
+		merged.pushChange(e.label, ch)
+	}
+	return merged
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Check rules. Problems found by the rules listed in checkWarnings are only
+// reported as failures in strict mode.
+const (
+	ruleSyntax           = "syntax"
+	ruleUnknownLabel     = "unknown-label"
+	ruleMixedChanges     = "mixed-changes"
+	ruleDuplicateVersion = "duplicate-version"
+	ruleReleaseOrder     = "release-order"
+	ruleFutureDate       = "future-date"
+	ruleDateOrder        = "date-order"
+	ruleMissingLink      = "missing-link"
+	ruleBrokenLink       = "broken-link"
+	ruleEmptyRelease     = "empty-release"
+	ruleVersionFormat    = "version-format"
+)
+
+var checkRules = []string{
+	ruleSyntax,
+	ruleUnknownLabel,
+	ruleMixedChanges,
+	ruleDuplicateVersion,
+	ruleReleaseOrder,
+	ruleFutureDate,
+	ruleDateOrder,
+	ruleMissingLink,
+	ruleBrokenLink,
+	ruleEmptyRelease,
+	ruleVersionFormat,
+}
+
+var checkWarnings = map[string]bool{
+	ruleReleaseOrder:  true,
+	ruleFutureDate:    true,
+	ruleDateOrder:     true,
+	ruleEmptyRelease:  true,
+	ruleVersionFormat: true,
+}
+
+type checkIssue struct {
+	line int
+	rule string
+	msg  string
+}
+
+func (i checkIssue) warning() bool {
+	return checkWarnings[i.rule]
+}
+
+func (i checkIssue) format(name string) string {
+	msg := i.msg
+	if i.warning() {
+		msg = "warning: " + msg
+	}
+	return fmt.Sprintf("%s:%d: %s [%s]", name, i.line, msg, i.rule)
+}
+
+type changelogChecker struct {
+	config   *config
+	parser   *changelogParser
+	log      *changelog
+	today    string
+	disabled map[string]bool
+	issues   []checkIssue
+}
+
+// checkChangelog reports the problems found in the changelog at path, ordered
+// by line number. Unlike parseChangelog, it does not stop at syntax errors.
+func checkChangelog(path string, cfg *config, now time.Time) ([]checkIssue, error) {
+	disabled := make(map[string]bool)
+	for _, rule := range cfg.Check.Disable {
+		if !contains(checkRules, rule) {
+			return nil, fmt.Errorf("no such check rule: %s, try: %s", rule, strings.Join(checkRules, " | "))
+		}
+		disabled[rule] = true
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p := newChangelogParser(path, cfg)
+	log, err := p.parse(f)
+	if _, ok := err.(parseError); err != nil && !ok {
+		return nil, err
+	}
+	c := &changelogChecker{
+		config:   cfg,
+		parser:   p,
+		log:      log,
+		today:    now.Format(iso8601),
+		disabled: disabled,
+	}
+	for _, check := range []func(){
+		c.checkSyntax,
+		c.checkHeadings,
+		c.checkLinks,
+	} {
+		check()
+	}
+	sort.SliceStable(c.issues, func(i, j int) bool {
+		return c.issues[i].line < c.issues[j].line
+	})
+	return c.issues, nil
+}
+
+func (c *changelogChecker) report(line int, rule string, fs string, args ...interface{}) {
+	if c.disabled[rule] {
+		return
+	}
+	c.issues = append(c.issues, checkIssue{
+		line: line,
+		rule: rule,
+		msg:  fmt.Sprintf(fs, args...),
+	})
+}
+
+func (c *changelogChecker) checkSyntax() {
+	for _, e := range c.parser.errs {
+		rule := ruleSyntax
+		switch e.err.(type) {
+		case unknownLabelError:
+			rule = ruleUnknownLabel
+		case orphanLinkError:
+			rule = ruleBrokenLink
+		}
+		if e.err == errIncompatChanges {
+			rule = ruleMixedChanges
+		}
+		c.report(e.line, rule, "%s", e.err)
+	}
+}
+
+func (c *changelogChecker) checkHeadings() {
+	var (
+		seen = make(map[*release]bool)
+		prev *releaseHeading // the previous versioned release
+	)
+	for i := range c.parser.headings {
+		h := &c.parser.headings[i]
+		rel := h.rel
+		if seen[rel] {
+			c.report(h.line, ruleDuplicateVersion, "duplicate release heading: %s", rel.version)
+			continue
+		}
+		seen[rel] = true
+		if strings.HasPrefix(h.text, "[") && rel.link == "" {
+			c.report(h.line, ruleMissingLink, "release heading references a missing link: %s", rel.version)
+		}
+		if rel.unreleased() {
+			if prev != nil {
+				c.report(h.line, ruleReleaseOrder, "the Unreleased section must precede all releases")
+			}
+			continue
+		}
+
+		if scheme := c.log.versioning(); !scheme.wellFormed(rel.version) {
+			c.report(h.line, ruleVersionFormat, "version does not adhere to %s: %s", scheme, rel.version)
+		}
+		if rel.changeCount() == 0 && rel.note == "" {
+			c.report(h.line, ruleEmptyRelease, "release has no changes: %s", rel.version)
+		}
+		if !rel.date.IsZero() && rel.date.Format(iso8601) > c.today {
+			c.report(h.line, ruleFutureDate, "release is dated in the future: %s", rel.date.Format(iso8601))
+		}
+		if prev != nil {
+			c.checkOrder(prev, h)
+		}
+		prev = h
+	}
+}
+
+// checkOrder checks that the release of h is older than the release of prev,
+// which precedes it in the changelog.
+func (c *changelogChecker) checkOrder(prev, h *releaseHeading) {
+	a, b := prev.rel, h.rel
+	scheme := c.log.versioning()
+	if scheme.valid(a.version) && scheme.valid(b.version) {
+		switch scheme.compare(a.version, b.version) {
+		case 0:
+			c.report(h.line, ruleDuplicateVersion, "release %s has the same precedence as %s", b.version, a.version)
+		case -1:
+			c.report(h.line, ruleReleaseOrder, "release %s must precede %s", b.version, a.version)
+		}
+	}
+	if !a.date.IsZero() && !b.date.IsZero() && b.date.After(a.date) {
+		c.report(h.line, ruleDateOrder, "release %s is dated after the newer release %s: %s > %s",
+			b.version, a.version, b.date.Format(iso8601), a.date.Format(iso8601))
+	}
+}
+
+func (c *changelogChecker) checkLinks() {
+	for _, ref := range c.parser.links {
+		u, err := url.Parse(ref.link)
+		switch {
+		case err != nil:
+			c.report(ref.line, ruleBrokenLink, "invalid release link (%s): %s", ref.version, ref.link)
+		case u.IsAbs() && u.Host == "" && u.Opaque == "":
+			c.report(ref.line, ruleBrokenLink, "release link (%s) is missing a host: %s", ref.version, ref.link)
+		case strings.ContainsAny(ref.link, "{}"):
+			c.report(ref.line, ruleBrokenLink, "release link (%s) contains a placeholder: %s", ref.version, ref.link)
+		}
+	}
+}
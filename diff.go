@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContext is the number of unchanged lines that surround each hunk.
+const diffContext = 3
+
+type diffLine struct {
+	op   byte // ' ', '-' or '+'
+	text string
+}
+
+// unifiedDiff returns the differences between a and b in the unified format,
+// or an empty string if there are none. The name of the compared file is
+// prefixed by "a/" and "b/" in the diff header, like git does.
+func unifiedDiff(name, a, b string) string {
+	if a == b {
+		return ""
+	}
+	lines := diffLines(a, b)
+
+	// pos[i] holds the line numbers in a and b at which lines[i] is found.
+	pos := make([][2]int, len(lines)+1)
+	for i, l := range lines {
+		pos[i+1] = pos[i]
+		if l.op != '+' {
+			pos[i+1][0]++
+		}
+		if l.op != '-' {
+			pos[i+1][1]++
+		}
+	}
+
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, "--- a/%s\n+++ b/%s\n", name, name)
+	for i := 0; i < len(lines); {
+		for i < len(lines) && lines[i].op == ' ' {
+			i++
+		}
+		if i == len(lines) {
+			break
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for {
+			for end < len(lines) && lines[end].op != ' ' {
+				end++
+			}
+			next := end
+			for next < len(lines) && lines[next].op == ' ' {
+				next++
+			}
+			// Merge hunks whose contexts would overlap.
+			if next < len(lines) && next-end <= 2*diffContext {
+				end = next
+				continue
+			}
+			end += diffContext
+			if end > len(lines) {
+				end = len(lines)
+			}
+			break
+		}
+		fmt.Fprintf(buf, "@@ -%s +%s @@\n",
+			hunkRange(pos[start][0], pos[end][0]-pos[start][0]),
+			hunkRange(pos[start][1], pos[end][1]-pos[start][1]),
+		)
+		for _, l := range lines[start:end] {
+			buf.WriteByte(l.op)
+			buf.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return buf.String()
+}
+
+// hunkRange formats the range of a hunk that starts after line n.
+func hunkRange(n, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", n)
+	case 1:
+		return fmt.Sprintf("%d", n+1)
+	default:
+		return fmt.Sprintf("%d,%d", n+1, count)
+	}
+}
+
+func diffLines(a, b string) (lines []diffLine) {
+	dmp := diffmatchpatch.New()
+	ca, cb, index := dmp.DiffLinesToChars(a, b)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(ca, cb, false), index)
+	for _, d := range diffs {
+		op := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+		text := d.Text
+		for text != "" {
+			n := strings.IndexByte(text, '\n') + 1
+			if n == 0 {
+				n = len(text)
+			}
+			lines = append(lines, diffLine{op, text[:n]})
+			text = text[n:]
+		}
+	}
+	return
+}
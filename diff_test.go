@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestUnifiedDiff(t *testing.T) {
+	for _, test := range []struct {
+		a, b, exp string
+	}{
+		{
+			a: "a\nb\n",
+			b: "a\nb\n",
+		},
+		{
+			a: "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n",
+			b: "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n",
+			exp: "--- a/f\n+++ b/f\n" +
+				"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+				"@@ -8,3 +8,4 @@\n h\n i\n j\n+k\n",
+		},
+		{
+			a: "a\nb",
+			b: "a\n",
+			exp: "--- a/f\n+++ b/f\n" +
+				"@@ -1,2 +1 @@\n a\n-b\n\\ No newline at end of file\n",
+		},
+		{
+			a:   "",
+			b:   "a\n",
+			exp: "--- a/f\n+++ b/f\n@@ -0,0 +1 @@\n+a\n",
+		},
+	} {
+		if got := unifiedDiff("f", test.a, test.b); got != test.exp {
+			t.Errorf("%q -> %q:\n%s", test.a, test.b, diff(test.exp, got))
+		}
+	}
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	htmltemplate "html/template"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Export formats.
+const (
+	exportHTML   = "html"
+	exportAtom   = "atom"
+	exportRSS    = "rss"
+	exportDebian = "debian"
+	exportRPM    = "rpm"
+)
+
+var exportFormats = []string{exportHTML, exportAtom, exportRSS, exportDebian, exportRPM}
+
+// exportDoc is the data that export templates are executed with.
+type exportDoc struct {
+	changelogDoc
+	Feed      feedDoc
+	Packaging packagingDoc
+
+	scheme versionScheme
+}
+
+// Upstream returns ver as a package version, i.e., without its prefix and
+// with pre-release versions marked by a tilde, which sorts before anything,
+// even the end of the version, in both Debian and RPM versions.
+func (doc exportDoc) Upstream(ver string) string {
+	if doc.scheme != nil {
+		_, ver = splitVersion(doc.scheme, ver)
+	}
+	return strings.Replace(ver, "-", "~", 1)
+}
+
+type feedDoc struct {
+	Title   string
+	ID      string
+	URL     string
+	Updated string // the date of the latest release or today
+}
+
+// exportTemplate returns the template for the export format, which is read
+// from the file specified in the export.templates table, if any, or built in.
+func (c *config) exportTemplate(format string) (string, error) {
+	path := c.Export.Templates[format]
+	if path == "" {
+		return exportTemplates[format], nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(c.path), path)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// feed documents the feed of doc as configured by the feed table. The URL is
+// required by RSS, while Atom requires the ID, which defaults to the URL.
+func (c *config) feed(format string, doc changelogDoc) (feedDoc, error) {
+	feed := feedDoc{
+		Title: c.Feed.Title,
+		ID:    c.Feed.ID,
+		URL:   c.Feed.URL,
+	}
+	if feed.Title == "" {
+		feed.Title = doc.Title
+	}
+	if feed.ID == "" {
+		feed.ID = feed.URL
+	}
+	switch {
+	case format == exportAtom && feed.ID == "":
+		return feed, errors.New("missing feed.id or feed.url")
+	case format == exportRSS && feed.URL == "":
+		return feed, errors.New("missing feed.url")
+	}
+	for _, rel := range doc.Releases {
+		if rel.Date > feed.Updated {
+			feed.Updated = rel.Date
+		}
+	}
+	if feed.Updated == "" {
+		feed.Updated = time.Now().UTC().Format(iso8601)
+	}
+	return feed, nil
+}
+
+type packagingDoc struct {
+	Name         string
+	Maintainer   string
+	Email        string
+	Distribution string
+	Urgency      string
+	Revision     string
+}
+
+// packaging documents the package described by the packaging table. The
+// maintainer is required by all packaging formats, while the package name is
+// only required by Debian, since an RPM changelog is part of a spec file that
+// names the package. All exported releases must be dated.
+func (c *config) packaging(format string, doc changelogDoc) (packagingDoc, error) {
+	pkg := packagingDoc{
+		Name:         c.Packaging.Name,
+		Maintainer:   c.Packaging.Maintainer,
+		Email:        c.Packaging.Email,
+		Distribution: c.Packaging.Distribution,
+		Urgency:      c.Packaging.Urgency,
+		Revision:     c.Packaging.Revision,
+	}
+	if pkg.Distribution == "" {
+		pkg.Distribution = "unstable"
+	}
+	if pkg.Urgency == "" {
+		pkg.Urgency = "medium"
+	}
+	if pkg.Revision == "" {
+		pkg.Revision = "1"
+	}
+	switch {
+	case format == exportDebian && pkg.Name == "":
+		return pkg, errors.New("missing packaging.name")
+	case pkg.Maintainer == "":
+		return pkg, errors.New("missing packaging.maintainer")
+	case pkg.Email == "":
+		return pkg, errors.New("missing packaging.email")
+	}
+	for _, rel := range doc.Releases {
+		if rel.Date == "" {
+			return pkg, fmt.Errorf("%s: missing release date", rel.Version)
+		}
+	}
+	return pkg, nil
+}
+
+var reAnchorUnsafe = regexp.MustCompile(`[^[:alnum:]._-]+`)
+
+// anchor returns the HTML id of the release versioned ver.
+func anchor(ver string) string {
+	return strings.Trim(reAnchorUnsafe.ReplaceAllString(strings.ToLower(ver), "-"), "-")
+}
+
+// releaseHTML renders the note and changes of rel as HTML.
+func releaseHTML(rel releaseDoc) string {
+	var buf strings.Builder
+	if rel.Note != "" {
+		buf.WriteString(markdown(rel.Note) + "\n")
+	}
+	for _, g := range rel.Changes {
+		if g.Label != "" {
+			buf.WriteString("<h3>" + html.EscapeString(g.Label) + "</h3>\n")
+		}
+		buf.WriteString("<ul>\n")
+		for _, item := range g.Items {
+			buf.WriteString("<li>" + markdownInline(item) + "</li>\n")
+		}
+		buf.WriteString("</ul>\n")
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// formatDate formats the YYYY-MM-DD date as layout.
+func formatDate(layout, date string) (string, error) {
+	t, err := time.Parse(iso8601, date)
+	if err != nil {
+		return "", err
+	}
+	return t.Format(layout), nil
+}
+
+var xmlEscaper = strings.NewReplacer(
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	`"`, "&quot;",
+	"'", "&apos;",
+)
+
+// rpmEscaper escapes macros in the %changelog section of RPM spec files, which
+// rpmbuild expands even there.
+var rpmEscaper = strings.NewReplacer("%", "%%")
+
+// exportFuncs are available to all export templates.
+var exportFuncs = map[string]interface{}{
+	"anchor": anchor,
+	"atomDate": func(date string) (string, error) {
+		return formatDate(time.RFC3339, date)
+	},
+	"rssDate": func(date string) (string, error) {
+		return formatDate(time.RFC1123Z, date)
+	},
+	"debianDate": func(date string) (string, error) {
+		return formatDate(time.RFC1123Z, date)
+	},
+	"rpmDate": func(date string) (string, error) {
+		return formatDate("Mon Jan 02 2006", date)
+	},
+	"indent": func(n int, s string) string {
+		return strings.Replace(s, "\n", "\n"+strings.Repeat(" ", n), -1)
+	},
+}
+
+// export renders doc as format via the template tmpl. HTML is rendered via
+// html/template, which escapes all values, while other formats are rendered
+// via text/template and have to escape values themselves, e.g., via the xml
+// or rpm functions.
+func export(w io.Writer, format, tmpl string, doc exportDoc) error {
+	tmpl = strings.TrimSpace(tmpl) + "\n"
+	if format == exportHTML {
+		funcs := htmltemplate.FuncMap{
+			"markdown": func(s string) htmltemplate.HTML {
+				return htmltemplate.HTML(markdown(s))
+			},
+			"inline": func(s string) htmltemplate.HTML {
+				return htmltemplate.HTML(markdownInline(s))
+			},
+			"content": func(rel releaseDoc) htmltemplate.HTML {
+				return htmltemplate.HTML(releaseHTML(rel))
+			},
+		}
+		for name, fn := range exportFuncs {
+			funcs[name] = fn
+		}
+		t, err := htmltemplate.New(format).Funcs(funcs).Parse(tmpl)
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, doc)
+	}
+	funcs := template.FuncMap{
+		"markdown": markdown,
+		"inline":   markdownInline,
+		"content":  releaseHTML,
+		"xml":      xmlEscaper.Replace,
+		"rpm":      rpmEscaper.Replace,
+	}
+	for name, fn := range exportFuncs {
+		funcs[name] = fn
+	}
+	t, err := template.New(format).Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, doc)
+}
+
+var exportTemplates = templates{
+	exportHTML: `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{ or .Title "Changelog" }}</title>
+<style>
+  body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.5; max-width: 50em; margin: 2em auto; padding: 0 1em; color: #24292e; }
+  a { color: #0366d6; text-decoration: none; }
+  a:hover { text-decoration: underline; }
+  code { font-family: SFMono-Regular, Consolas, Menlo, monospace; font-size: 90%; background: #f3f4f4; padding: .1em .3em; border-radius: 3px; }
+  nav ul { list-style: none; padding-left: 0; }
+  h2 { border-bottom: 1px solid #eaecef; padding-bottom: .3em; margin-top: 2em; }
+  .date { color: #6a737d; font-weight: normal; font-size: 80%; }
+  .yanked { color: #cb2431; font-size: 70%; vertical-align: middle; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{ or .Title "Changelog" }}</h1>
+{{ with .Header }}{{ markdown . }}{{ end }}
+</header>
+<nav>
+<h2>Releases</h2>
+<ul>
+{{- range .Releases }}
+<li><a href="#{{ anchor .Version }}">{{ .Version }}</a>{{ with .Date }} <span class="date">{{ . }}</span>{{ end }}</li>
+{{- end }}
+</ul>
+</nav>
+<main>
+{{- range .Releases }}
+<section id="{{ anchor .Version }}">
+<h2>{{ if .Link }}<a href="{{ .Link }}">{{ .Version }}</a>{{ else }}{{ .Version }}{{ end }}
+{{- with .Date }} <span class="date">{{ . }}</span>{{ end }}
+{{- if .Yanked }} <span class="yanked">YANKED</span>{{ end }}</h2>
+{{- with .Note }}
+{{ markdown . }}
+{{- end }}
+{{- range .Changes }}
+{{- with .Label }}
+<h3>{{ . }}</h3>
+{{- end }}
+<ul>
+{{- range .Items }}
+<li>{{ inline . }}</li>
+{{- end }}
+</ul>
+{{- end }}
+</section>
+{{- end }}
+</main>
+</body>
+</html>`,
+	exportAtom: `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>{{ xml (or .Feed.Title "Changelog") }}</title>
+<id>{{ xml .Feed.ID }}</id>
+{{- with .Feed.URL }}
+<link href="{{ xml . }}"/>
+{{- end }}
+<updated>{{ atomDate .Feed.Updated }}</updated>
+{{- range .Releases }}
+<entry>
+<title>{{ xml .Version }}{{ if .Yanked }} [YANKED]{{ end }}</title>
+<id>{{ xml $.Feed.ID }}#{{ anchor .Version }}</id>
+{{- if .Link }}
+<link href="{{ xml .Link }}"/>
+{{- else if $.Feed.URL }}
+<link href="{{ xml $.Feed.URL }}#{{ anchor .Version }}"/>
+{{- end }}
+<updated>{{ atomDate (or .Date $.Feed.Updated) }}</updated>
+<content type="html">{{ xml (content .) }}</content>
+</entry>
+{{- end }}
+</feed>`,
+	exportRSS: `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0">
+<channel>
+<title>{{ xml (or .Feed.Title "Changelog") }}</title>
+<link>{{ xml .Feed.URL }}</link>
+<description>{{ xml (or .Header .Feed.Title "Changelog") }}</description>
+<lastBuildDate>{{ rssDate .Feed.Updated }}</lastBuildDate>
+{{- range .Releases }}
+<item>
+<title>{{ xml .Version }}{{ if .Yanked }} [YANKED]{{ end }}</title>
+<link>{{ with .Link }}{{ xml . }}{{ else }}{{ xml $.Feed.URL }}#{{ anchor .Version }}{{ end }}</link>
+<guid isPermaLink="false">{{ xml $.Feed.ID }}#{{ anchor .Version }}</guid>
+{{- with .Date }}
+<pubDate>{{ rssDate . }}</pubDate>
+{{- end }}
+<description>{{ xml (content .) }}</description>
+</item>
+{{- end }}
+</channel>
+</rss>`,
+	exportDebian: `{{ range $i, $rel := .Releases }}{{ if $i }}
+{{ end -}}
+{{ $.Packaging.Name }} ({{ $.Upstream .Version }}-{{ $.Packaging.Revision }}) {{ $.Packaging.Distribution }}; urgency={{ $.Packaging.Urgency }}
+{{ range .Changes }}
+{{- if .Label }}
+  * {{ .Label }}:
+{{- range .Items }}
+    - {{ indent 6 . }}
+{{- end }}
+{{- else }}
+{{- range .Items }}
+  * {{ indent 4 . }}
+{{- end }}
+{{- end }}
+{{- else }}
+  * New upstream release.
+{{- end }}
+
+ -- {{ $.Packaging.Maintainer }} <{{ $.Packaging.Email }}>  {{ debianDate .Date }}
+{{ end -}}`,
+	exportRPM: `{{ range $i, $rel := .Releases }}{{ if $i }}
+{{ end -}}
+* {{ rpmDate .Date }} {{ rpm $.Packaging.Maintainer }} <{{ rpm $.Packaging.Email }}> - {{ rpm ($.Upstream .Version) }}-{{ rpm $.Packaging.Revision }}
+{{- range .Changes }}
+{{- if .Label }}
+- {{ rpm .Label }}:
+{{- range .Items }}
+  - {{ indent 4 (rpm .) }}
+{{- end }}
+{{- else }}
+{{- range .Items }}
+- {{ indent 2 (rpm .) }}
+{{- end }}
+{{- end }}
+{{- else }}
+- New upstream release.
+{{- end }}
+{{ end -}}`,
+}
@@ -0,0 +1,64 @@
+package main
+
+import "testing"
+
+func TestAnchor(t *testing.T) {
+	for ver, exp := range map[string]string{
+		"1.0.0":            "1.0.0",
+		"v2.0.0-RC.1+Exp":  "v2.0.0-rc.1-exp",
+		"Release 2020/01":  "release-2020-01",
+		"(weird) version!": "weird-version",
+	} {
+		if got := anchor(ver); got != exp {
+			t.Errorf("%q: expected %q, got %q", ver, exp, got)
+		}
+	}
+}
+
+func TestFeed(t *testing.T) {
+	cfg := newConfig()
+	cfg.Feed.URL = "https://example.com/"
+	doc := changelogDoc{
+		Title: "Changelog",
+		Releases: []releaseDoc{
+			{Version: "1.1.0"},
+			{Version: "1.0.1", Date: "2020-03-01"},
+			{Version: "1.0.0", Date: "2020-01-01"},
+		},
+	}
+	feed, err := cfg.feed(exportAtom, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := feedDoc{
+		Title:   "Changelog",
+		ID:      "https://example.com/",
+		URL:     "https://example.com/",
+		Updated: "2020-03-01",
+	}
+	if feed != exp {
+		t.Errorf("expected %+v, got %+v", exp, feed)
+	}
+	cfg.Feed.URL = ""
+	cfg.Feed.ID = "urn:kc:test"
+	if _, err := cfg.feed(exportAtom, doc); err != nil {
+		t.Error(err)
+	}
+	if _, err := cfg.feed(exportRSS, doc); err == nil {
+		t.Error("expected an error for a missing URL")
+	}
+}
+
+func TestUpstream(t *testing.T) {
+	doc := exportDoc{scheme: prefixedScheme{semverScheme{}, "v"}}
+	for ver, exp := range map[string]string{
+		"v1.0.0":            "1.0.0",
+		"v1.0.0-rc.1":       "1.0.0~rc.1",
+		"v1.0.0-rc-1+build": "1.0.0~rc-1+build",
+		"1.0.0":             "1.0.0",
+	} {
+		if got := doc.Upstream(ver); got != exp {
+			t.Errorf("%q: expected %q, got %q", ver, exp, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// releaseFilter narrows down releases by date and their changes by label and
+// text, as specified via --since, --until, --label and --grep.
+type releaseFilter struct {
+	since, until time.Time
+	label        string
+	re           *regexp.Regexp
+}
+
+// filter returns the filter specified by the options of inv. The label is
+// matched against labels, if any, so that it may be given as a prefix.
+func (inv *invocation) filter(labels []string) (*releaseFilter, error) {
+	f := new(releaseFilter)
+	for _, opt := range []struct {
+		name string
+		val  string
+		t    *time.Time
+	}{
+		{"since", inv.opts.since, &f.since},
+		{"until", inv.opts.until, &f.until},
+	} {
+		if opt.val == "" {
+			continue
+		}
+		t, err := time.Parse(iso8601, dateSeparator.Replace(opt.val))
+		if err != nil {
+			return nil, fmt.Errorf("invalid --%s date: %s, expected YYYY-MM-DD", opt.name, opt.val)
+		}
+		*opt.t = t
+	}
+	f.label = inv.opts.label
+	if f.label != "" && len(labels) > 0 {
+		label, err := prefix(f.label).matchAs(labels, "change label")
+		if err != nil {
+			return nil, err
+		}
+		f.label = label
+	}
+	if expr := inv.opts.grep; expr != "" {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("--grep: %s", err)
+		}
+		f.re = re
+	}
+	return f, nil
+}
+
+func (f *releaseFilter) empty() bool {
+	return !f.dated() && !f.narrows()
+}
+
+// dated reports whether f filters releases by date.
+func (f *releaseFilter) dated() bool {
+	return !f.since.IsZero() || !f.until.IsZero()
+}
+
+// narrows reports whether f filters the changes of releases.
+func (f *releaseFilter) narrows() bool {
+	return f.label != "" || f.re != nil
+}
+
+// inRange reports whether rel is dated within the range of f, both ends of
+// which are inclusive. Undated releases, such as the Unreleased section, are
+// out of range unless f has no range.
+func (f *releaseFilter) inRange(rel *release) bool {
+	switch {
+	case !f.dated():
+		return true
+	case rel.date.IsZero():
+		return false
+	case !f.since.IsZero() && rel.date.Before(f.since):
+		return false
+	case !f.until.IsZero() && rel.date.After(f.until):
+		return false
+	}
+	return true
+}
+
+// apply returns the releases of rs that are within range. If f narrows
+// changes, the releases are copies that only hold the matching changes, and
+// releases without any are dropped. Release notes are only retained if they
+// match --grep and no label is given.
+func (f *releaseFilter) apply(rs releases) (res releases) {
+	for _, rel := range rs.filter(f.inRange) {
+		if !f.narrows() {
+			res = append(res, rel)
+			continue
+		}
+		cp := *rel
+		cp.note = ""
+		cp.changes = nil
+		if f.label == "" && f.re.MatchString(rel.note) {
+			cp.note = rel.note
+		}
+		for _, g := range rel.changes {
+			if f.label != "" && !strings.EqualFold(g.label, f.label) {
+				continue
+			}
+			for _, ch := range g.changes {
+				if f.re == nil || f.re.MatchString(ch) {
+					cp.pushChange(g.label, ch)
+				}
+			}
+		}
+		if cp.note != "" || cp.changeCount() > 0 {
+			res = append(res, &cp)
+		}
+	}
+	return
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Output formats.
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+var formats = []string{formatText, formatJSON, formatYAML}
+
+// The following types define the schema of the machine-readable output
+// formats. Fields are never omitted, so that consumers may rely on their
+// presence; empty strings denote missing values.
+
+type changelogDoc struct {
+	Title    string       `json:"title"`
+	Header   string       `json:"header"`
+	Releases []releaseDoc `json:"releases"`
+}
+
+type releaseDoc struct {
+	Version    string       `json:"version"`
+	Unreleased bool         `json:"unreleased"`
+	Date       string       `json:"date"`
+	Yanked     bool         `json:"yanked"`
+	Link       string       `json:"link"`
+	Note       string       `json:"note"`
+	Changes    []changesDoc `json:"changes"`
+}
+
+type changesDoc struct {
+	Label string   `json:"label"`
+	Items []string `json:"items"`
+}
+
+type releaseSummaryDoc struct {
+	Version    string `json:"version"`
+	Unreleased bool   `json:"unreleased"`
+	Date       string `json:"date"`
+	Yanked     bool   `json:"yanked"`
+	Changes    int    `json:"changes"`
+}
+
+type componentDoc struct {
+	Name      string              `json:"name"`
+	Changelog string              `json:"changelog"`
+	Releases  []releaseSummaryDoc `json:"releases"`
+	Changes   []changesDoc        `json:"changes"`
+}
+
+type statsDoc struct {
+	Releases       []releaseSummaryDoc `json:"releases"`
+	Labels         []labelStatsDoc     `json:"labels"`
+	Cadence        *durationStatsDoc   `json:"cadence"`
+	LongestGap     *gapDoc             `json:"longest_gap"`
+	UnreleasedTime *durationStatsDoc   `json:"unreleased_time"`
+	Mentions       []mentionStatsDoc   `json:"mentions"`
+}
+
+type labelStatsDoc struct {
+	Label   string `json:"label"`
+	Changes int    `json:"changes"`
+}
+
+type durationStatsDoc struct {
+	MeanDays   float64 `json:"mean_days"`
+	MedianDays float64 `json:"median_days"`
+}
+
+type gapDoc struct {
+	From string  `json:"from"`
+	To   string  `json:"to"`
+	Days float64 `json:"days"`
+}
+
+type mentionStatsDoc struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func (r *changelogRenderer) changelogDoc() changelogDoc {
+	return changelogDoc{
+		Title:    r.log.title,
+		Header:   r.interpolateMentions(r.log.header),
+		Releases: r.releaseDocs(r.log.releases),
+	}
+}
+
+// releaseDocs documents rs, whose versions must be found among the releases
+// of the rendered changelog, so that their links can be generated.
+func (r *changelogRenderer) releaseDocs(rs releases) []releaseDoc {
+	docs := make([]releaseDoc, 0, len(rs))
+	for _, rel := range rs {
+		doc := releaseDoc{
+			Version:    rel.version,
+			Unreleased: rel.unreleased(),
+			Yanked:     rel.yanked,
+			Link:       rel.link,
+			Note:       r.interpolateMentions(rel.note),
+		}
+		for i, other := range r.log.releases {
+			if other.version == rel.version {
+				doc.Link = r.releaseLink(i)
+				break
+			}
+		}
+		if !rel.date.IsZero() {
+			doc.Date = rel.date.Format(iso8601)
+		}
+		doc.Changes = r.changesDocs(rel.changes)
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func (r *changelogRenderer) changesDocs(cs changeSet) []changesDoc {
+	docs := []changesDoc{}
+	for _, g := range cs.sorted(r.config.Changes.Labels) {
+		items := make([]string, len(g.changes))
+		for i, ch := range g.changes {
+			items[i] = r.interpolateMentions(ch)
+		}
+		docs = append(docs, changesDoc{
+			Label: g.label,
+			Items: items,
+		})
+	}
+	return docs
+}
+
+func releaseSummaryDocs(rs releases) []releaseSummaryDoc {
+	docs := make([]releaseSummaryDoc, 0, len(rs))
+	for _, rel := range rs {
+		doc := releaseSummaryDoc{
+			Version:    rel.version,
+			Unreleased: rel.unreleased(),
+			Yanked:     rel.yanked,
+			Changes:    rel.changeCount(),
+		}
+		if !rel.date.IsZero() {
+			doc.Date = rel.date.Format(iso8601)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// encode writes v to w according to format, which must be one of formatJSON
+// or formatYAML.
+func encode(w io.Writer, format string, v interface{}) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		enc := &yamlEncoder{}
+		enc.encode(reflect.ValueOf(v))
+		_, err := w.Write(enc.Bytes())
+		return err
+	default:
+		panicf("encode: unexpected format: %s", format)
+	}
+	return nil
+}
+
+// yamlEncoder is a minimal YAML encoder for the document types above, i.e.,
+// structs, slices and scalars. Strings are always double-quoted, which makes
+// their JSON representation valid YAML.
+type yamlEncoder struct {
+	bytes.Buffer
+}
+
+func (e *yamlEncoder) encode(v reflect.Value) {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		e.mapping(v, 0, false)
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			e.WriteString("[]\n")
+		}
+		for i := 0; i < v.Len(); i++ {
+			e.WriteString("- ")
+			e.item(v.Index(i), 2)
+		}
+	default:
+		e.scalar(v)
+		e.WriteByte('\n')
+	}
+}
+
+// mapping writes the fields of struct v at the given indentation. If inline
+// is set, the first field is written on the current line.
+func (e *yamlEncoder) mapping(v reflect.Value, indent int, inline bool) {
+	t := v.Type()
+	var n int
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" || f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if n > 0 || !inline {
+			e.pad(indent)
+		}
+		fmt.Fprintf(e, "%s:", name)
+		e.node(v.Field(i), indent)
+		n++
+	}
+	if n == 0 {
+		e.WriteString("{}\n")
+	}
+}
+
+// node writes v following a mapping key found at the given indentation.
+func (e *yamlEncoder) node(v reflect.Value, indent int) {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		e.WriteByte('\n')
+		e.mapping(v, indent+2, false)
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			e.WriteString(" []\n")
+			return
+		}
+		e.WriteByte('\n')
+		for i := 0; i < v.Len(); i++ {
+			e.pad(indent + 2)
+			e.WriteString("- ")
+			e.item(v.Index(i), indent+4)
+		}
+	default:
+		e.WriteByte(' ')
+		e.scalar(v)
+		e.WriteByte('\n')
+	}
+}
+
+// item writes v following a sequence item marker.
+func (e *yamlEncoder) item(v reflect.Value, indent int) {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		e.mapping(v, indent, true)
+	case reflect.Slice, reflect.Array:
+		// Fall back to the flow style for nested sequences.
+		data, err := json.Marshal(v.Interface())
+		if err != nil {
+			panic(err)
+		}
+		e.Write(data)
+		e.WriteByte('\n')
+	default:
+		e.scalar(v)
+		e.WriteByte('\n')
+	}
+}
+
+func (e *yamlEncoder) scalar(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		e.WriteString(strconv.Quote(v.String()))
+	case reflect.Bool:
+		e.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		e.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Invalid:
+		e.WriteString("null")
+	default:
+		panicf("yamlEncoder.scalar: unexpected kind: %s", v.Kind())
+	}
+}
+
+func (e *yamlEncoder) pad(n int) {
+	e.WriteString(strings.Repeat(" ", n))
+}
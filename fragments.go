@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// A fragment is a change stored in a file of its own, rather than in the
+// Unreleased section of the changelog, so that concurrent branches do not
+// conflict when introducing changes. Fragments are stored under the directory
+// specified by fragments.dir, in a subdirectory named after their lowercased
+// change label, unless change labels are disabled.
+type fragment struct {
+	path  string
+	label string
+	text  string
+}
+
+const fragmentExt = ".md"
+
+// fragmentsDir returns the fragments directory for the changelog found at
+// path and whether fragments are enabled at all.
+func (c *config) fragmentsDir(path string) (string, bool) {
+	dir := c.Fragments.Dir
+	if dir == "" {
+		return "", false
+	}
+	if filepath.IsAbs(dir) {
+		return dir, true
+	}
+	return filepath.Join(filepath.Dir(path), dir), true
+}
+
+// loadFragments returns the fragments found in dir, ordered by label and file
+// name. A missing directory contains no fragments.
+func loadFragments(dir string, cfg *config) ([]fragment, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var frags []fragment
+	for _, info := range infos {
+		path := filepath.Join(dir, info.Name())
+		switch {
+		case info.IsDir():
+			if len(cfg.Changes.Labels) == 0 {
+				return nil, fmt.Errorf("unexpected fragment directory, since change labels are disabled: %s", path)
+			}
+			label, ok := cfg.label(info.Name())
+			if !ok {
+				return nil, fmt.Errorf("fragment directory does not match any change label: %s", path)
+			}
+			fs, err := loadFragmentFiles(path, label)
+			if err != nil {
+				return nil, err
+			}
+			frags = append(frags, fs...)
+		case filepath.Ext(path) == fragmentExt:
+			if len(cfg.Changes.Labels) > 0 {
+				return nil, fmt.Errorf("unlabeled fragment, expected it in a label directory: %s", path)
+			}
+			frag, err := loadFragment(path, keyUnlabeled)
+			if err != nil {
+				return nil, err
+			}
+			if frag.text != "" {
+				frags = append(frags, frag)
+			}
+		}
+	}
+	return frags, nil
+}
+
+func loadFragmentFiles(dir, label string) (frags []fragment, err error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range infos {
+		path := filepath.Join(dir, info.Name())
+		if info.IsDir() || filepath.Ext(path) != fragmentExt {
+			continue
+		}
+		frag, err := loadFragment(path, label)
+		if err != nil {
+			return nil, err
+		}
+		if frag.text != "" {
+			frags = append(frags, frag)
+		}
+	}
+	return
+}
+
+func loadFragment(path, label string) (fragment, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fragment{}, err
+	}
+	return fragment{
+		path:  path,
+		label: label,
+		text:  normalizeChange(string(data)),
+	}, nil
+}
+
+// writeFragment stores a new fragment in dir and returns its path. File names
+// start with a timestamp, so that fragments retain the order in which they
+// were introduced.
+func writeFragment(dir, label, text string) (string, error) {
+	dir = fragmentDir(dir, label)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := time.Now().UTC().Format("20060102T150405.000000000") + "-*" + fragmentExt
+	f, err := ioutil.TempFile(dir, name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(text + "\n"); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	return f.Name(), os.Chmod(f.Name(), 0644)
+}
+
+// fragmentDir returns the directory in dir that holds fragments labeled label.
+func fragmentDir(dir, label string) string {
+	if label == keyUnlabeled {
+		return dir
+	}
+	return filepath.Join(dir, strings.ToLower(label))
+}
+
+// removeFragments deletes frags, along with any label directories that are
+// left empty.
+func removeFragments(frags []fragment) error {
+	for _, frag := range frags {
+		if err := os.Remove(frag.path); err != nil {
+			return err
+		}
+		if frag.label != keyUnlabeled {
+			// Fails if other files remain, which is fine.
+			os.Remove(filepath.Dir(frag.path))
+		}
+	}
+	return nil
+}
+
+// pushFragments appends frags to the Unreleased section.
+func (l *changelog) pushFragments(frags []fragment) {
+	for _, frag := range frags {
+		l.pushChange(frag.label, frag.text)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFragments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := defaultConfig()
+	for _, frag := range []fragment{
+		{label: "Fixed", text: "a fix"},
+		{label: "Added", text: "a feature"},
+		{label: "Added", text: "another feature"},
+	} {
+		if _, err := writeFragment(dir, frag.label, frag.text); err != nil {
+			t.Fatal(err)
+		}
+	}
+	frags, err := loadFragments(dir, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, frag := range frags {
+		got = append(got, frag.label+": "+frag.text)
+	}
+	exp := []string{"Added: a feature", "Added: another feature", "Fixed: a fix"}
+	if len(got) != len(exp) {
+		t.Fatalf("expected %q, got %q", exp, got)
+	}
+	for i := range exp {
+		if got[i] != exp[i] {
+			t.Errorf("expected %q, got %q", exp[i], got[i])
+		}
+	}
+
+	if err := removeFragments(frags); err != nil {
+		t.Fatal(err)
+	}
+	for _, label := range []string{"added", "fixed"} {
+		if pathExists(filepath.Join(dir, label)) {
+			t.Errorf("label directory not removed: %s", label)
+		}
+	}
+}
+
+func TestNormalizeChange(t *testing.T) {
+	for text, exp := range map[string]string{
+		"a fix":                    "a fix",
+		"a fix\n\n\n":              "a fix",
+		"- a fix\n  continued\n\n": "a fix\n  continued",
+		"* a fix":                  "a fix",
+		"-":                        "",
+		"- \n\n":                   "",
+		"  \n":                     "",
+		"-a fix":                   "-a fix",
+		"--flag is gone":           "--flag is gone",
+	} {
+		if got := normalizeChange(text); got != exp {
+			t.Errorf("%q: expected %q, got %q", text, exp, got)
+		}
+	}
+}
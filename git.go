@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// git runs git with args in dir and returns its standard output.
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+type commit struct {
+	hash    string
+	message string
+}
+
+// gitLog returns the non-merge commits in rng, oldest first.
+func gitLog(dir, rng string) ([]commit, error) {
+	out, err := git(dir, "log", "--no-merges", "--reverse", "--format=%H%x00%B%x1e", rng)
+	if err != nil {
+		return nil, err
+	}
+	var commits []commit
+	for _, rec := range strings.Split(out, "\x1e") {
+		rec = strings.TrimSpace(rec)
+		if rec == "" {
+			continue
+		}
+		fields := strings.SplitN(rec, "\x00", 2)
+		if len(fields) != 2 {
+			return nil, errors.New("git log: unexpected output")
+		}
+		commits = append(commits, commit{
+			hash:    fields[0],
+			message: strings.TrimSpace(fields[1]),
+		})
+	}
+	return commits, nil
+}
+
+// conventionalCommit is a commit message that follows the Conventional
+// Commits specification (https://www.conventionalcommits.org/).
+type conventionalCommit struct {
+	typ         string
+	scope       string
+	description string
+	breaking    bool
+}
+
+var (
+	reCommitHeader   = regexp.MustCompile(`^([[:alnum:]-]+)(?:\(([^)]*)\))?(!)?:\s+(.+)$`)
+	reBreakingFooter = regexp.MustCompile(`(?m:^BREAKING[ -]CHANGE:\s)`)
+)
+
+func parseConventionalCommit(msg string) (cc conventionalCommit, ok bool) {
+	lines := strings.SplitN(msg, "\n", 2)
+	m := reCommitHeader.FindStringSubmatch(strings.TrimSpace(lines[0]))
+	if m == nil {
+		return cc, false
+	}
+	cc.typ = strings.ToLower(m[1])
+	cc.scope = m[2]
+	cc.breaking = m[3] != ""
+	cc.description = strings.TrimSpace(m[4])
+	if len(lines) > 1 && reBreakingFooter.MatchString(lines[1]) {
+		cc.breaking = true
+	}
+	return cc, true
+}
+
+// commitTypeBreaking is the key in the commits.types table that specifies the
+// label for breaking changes, regardless of their commit type.
+const commitTypeBreaking = "breaking"
+
+var defaultCommitTypes = map[string]string{
+	"feat":             "Added",
+	"fix":              "Fixed",
+	"security":         "Security",
+	"deprecate":        "Deprecated",
+	"remove":           "Removed",
+	commitTypeBreaking: "Changed",
+}
+
+func (c *config) commitTypes() map[string]string {
+	if c.Commits.Types == nil {
+		return defaultCommitTypes
+	}
+	return c.Commits.Types
+}
+
+// breakingLabel returns the change label that breaking commits are filed
+// under, if any.
+func (c *config) breakingLabel() (string, bool) {
+	label := c.commitTypes()[commitTypeBreaking]
+	if label == "" || len(c.Changes.Labels) == 0 {
+		return "", false
+	}
+	return c.label(label)
+}
+
+// commitLabel returns the change label for cc and whether cc should be
+// included in the changelog at all.
+func (c *config) commitLabel(cc conventionalCommit) (string, bool, error) {
+	types := c.commitTypes()
+	label, ok := types[cc.typ]
+	if cc.breaking {
+		if l, isSet := types[commitTypeBreaking]; isSet {
+			label, ok = l, true
+		}
+	}
+	if !ok || label == "" {
+		return "", false, nil
+	}
+	if len(c.Changes.Labels) == 0 {
+		return keyUnlabeled, true, nil
+	}
+	name, ok := c.label(label)
+	if !ok {
+		return "", false, fmt.Errorf("commit type %q maps to unknown change label: %q", cc.typ, label)
+	}
+	return name, true, nil
+}
+
+// gitBlameTimes returns the author time of each line of the file at path,
+// i.e., when the line was last modified. Uncommitted lines are attributed to
+// the current time.
+func gitBlameTimes(path string) ([]time.Time, error) {
+	out, err := git(filepath.Dir(path), "blame", "--line-porcelain", "--", filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	var times []time.Time
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "author-time ") {
+			continue
+		}
+		sec, err := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64)
+		if err != nil {
+			return nil, errors.New("git blame: unexpected output")
+		}
+		times = append(times, time.Unix(sec, 0))
+	}
+	return times, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestConventionalCommit(t *testing.T) {
+	for _, test := range []struct {
+		msg string
+		exp conventionalCommit
+		ok  bool
+	}{
+		{
+			msg: "feat: add a thing",
+			exp: conventionalCommit{typ: "feat", description: "add a thing"},
+			ok:  true,
+		},
+		{
+			msg: "Fix(parser):   handle empty labels",
+			exp: conventionalCommit{typ: "fix", scope: "parser", description: "handle empty labels"},
+			ok:  true,
+		},
+		{
+			msg: "refactor!: drop the old API",
+			exp: conventionalCommit{typ: "refactor", description: "drop the old API", breaking: true},
+			ok:  true,
+		},
+		{
+			msg: "feat: new flag\n\nSome text.\n\nBREAKING CHANGE: the old flag is gone",
+			exp: conventionalCommit{typ: "feat", description: "new flag", breaking: true},
+			ok:  true,
+		},
+		{
+			msg: "Update README",
+		},
+		{
+			msg: "feat:missing space",
+		},
+	} {
+		cc, ok := parseConventionalCommit(test.msg)
+		if ok != test.ok {
+			t.Errorf("%q: expected ok=%t, got %t", test.msg, test.ok, ok)
+			continue
+		}
+		if cc != test.exp {
+			t.Errorf("%q: expected %+v, got %+v", test.msg, test.exp, cc)
+		}
+	}
+}
+
+func TestFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer cd(t, cd(t, dir))
+
+	run := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=kc", "-c", "user.email=kc@localhost"}, args...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s", args, out)
+		}
+	}
+	changelog := noTabs(`# Changelog
+
+	## Unreleased
+
+	### Fixed
+
+	- handle empty labels
+
+	## 0.1.0
+	`)
+	if err := ioutil.WriteFile("CHANGELOG.md", []byte(changelog), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("init", "-q")
+	run("commit", "-q", "--allow-empty", "-m", "feat: ignored, since it precedes the tag")
+	run("tag", "0.1.0")
+	run("commit", "-q", "--allow-empty", "-m", "feat: add a thing")
+	run("commit", "-q", "--allow-empty", "-m", "docs: not imported")
+	run("commit", "-q", "--allow-empty", "-m", "fix(parser): handle empty labels")
+	run("commit", "-q", "--allow-empty", "-m", "refactor!: drop the old API")
+	run("commit", "-q", "--allow-empty", "-m", "fix: handle empty labels")
+
+	var stderr bytes.Buffer
+	inv := invocation{
+		stdin:  new(bytes.Buffer),
+		stdout: new(bytes.Buffer),
+		stderr: &stderr,
+	}
+	if err := inv.invoke([]string{"--from-git"}); err != nil {
+		t.Fatal(stderr.String())
+	}
+	data, err := ioutil.ReadFile("CHANGELOG.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := noTabs(`# Changelog
+
+	## Unreleased
+
+	### Added
+
+	- add a thing
+
+	### Changed
+
+	- drop the old API
+
+	### Fixed
+
+	- handle empty labels
+
+	## 0.1.0
+	`)
+	if diff := diff(exp, string(data)); diff != "" {
+		t.Errorf("\n%s", diff)
+	}
+
+	// The breaking change bumps the major number, since it is filed under the
+	// label of breaking commits.
+	var stdout bytes.Buffer
+	inv = invocation{
+		stdin:  new(bytes.Buffer),
+		stdout: &stdout,
+		stderr: &stderr,
+	}
+	if err := inv.invoke([]string{"--dry-run", "--release", "auto"}); err != nil {
+		t.Fatal(stderr.String())
+	}
+	if ver := strings.SplitN(stdout.String(), "\n", 2)[0]; ver != "1.0.0" {
+		t.Errorf("expected 1.0.0, got %s", ver)
+	}
+}
+
+func TestStatsUnreleasedTime(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer cd(t, cd(t, dir))
+
+	commit := func(date, changelog string) {
+		t.Helper()
+		if err := ioutil.WriteFile("CHANGELOG.md", []byte(noTabs(changelog)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{
+			{"add", "CHANGELOG.md"},
+			{"commit", "-q", "-m", date},
+		} {
+			args = append([]string{"-c", "user.name=kc", "-c", "user.email=kc@localhost"}, args...)
+			cmd := exec.Command("git", args...)
+			cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date+"T12:00:00Z")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %s: %s", args, out)
+			}
+		}
+	}
+	if out, err := exec.Command("git", "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %s", out)
+	}
+	commit("2020-01-01", `# Changelog
+	## Unreleased
+	- a
+	`)
+	commit("2020-01-05", `# Changelog
+	## Unreleased
+	- b
+	- a
+	`)
+	commit("2020-01-11", `# Changelog
+	## 1.0.0 - 2020-01-11
+	- b
+	- a
+	`)
+
+	var stdout, stderr bytes.Buffer
+	inv := invocation{
+		stdin:  new(bytes.Buffer),
+		stdout: &stdout,
+		stderr: &stderr,
+	}
+	if err := inv.invoke([]string{"--stats", "--format", "json"}); err != nil {
+		t.Fatal(stderr.String())
+	}
+	var doc statsDoc
+	if err := json.Unmarshal(stdout.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	exp := &durationStatsDoc{MeanDays: 8, MedianDays: 8}
+	if got := doc.UnreleasedTime; got == nil || *got != *exp {
+		t.Errorf("expected %+v, got %+v", exp, got)
+	}
+}
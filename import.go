@@ -0,0 +1,450 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Import formats.
+const (
+	importTowncrier = "towncrier"
+	importGitHub    = "github"
+	importDebian    = "debian"
+)
+
+var importFormats = []string{importTowncrier, importGitHub, importDebian}
+
+// importer parses the releases found in r, which is named name. The changes
+// of the releases are labeled by the categories of the source format, if any,
+// which are mapped onto change labels by config.importLabels.
+type importer func(name string, r io.Reader, scheme versionScheme) (releases, error)
+
+var importers = map[string]importer{
+	importTowncrier: importTowncrierNews,
+	importGitHub:    importGitHubReleases,
+	importDebian:    importDebianChangelog,
+}
+
+// importVersion returns ver if it is valid according to scheme, or ver
+// stripped of a "v" prefix, as is common for tags, if that is valid instead.
+func importVersion(scheme versionScheme, ver string) (string, bool) {
+	if scheme.valid(ver) {
+		return ver, true
+	}
+	if v := strings.TrimLeft(ver, "vV"); v != ver && scheme.valid(v) {
+		return v, true
+	}
+	return ver, false
+}
+
+var reTowncrierDate = regexp.MustCompile(`\s*\((\d{4}-\d{2}-\d{2})\)$`)
+
+// rstAdornment returns the character that line consists of, if it is an
+// underline or overline of a reStructuredText section title.
+func rstAdornment(line string) (byte, bool) {
+	line = strings.TrimRight(line, " \t")
+	if len(line) < 2 || !strings.ContainsRune("=-~^\"'*+#:.`", rune(line[0])) {
+		return 0, false
+	}
+	if strings.Trim(line, line[:1]) != "" {
+		return 0, false
+	}
+	return line[0], true
+}
+
+// towncrierTitle parses the version and optional date of a release title.
+func towncrierTitle(scheme versionScheme, title string) (ver, date string, err error) {
+	if m := reTowncrierDate.FindStringSubmatch(title); m != nil {
+		date = m[1]
+		title = title[:len(title)-len(m[0])]
+	}
+	fields := strings.Fields(title)
+	if len(fields) == 0 {
+		return "", "", errors.New("invalid release title")
+	}
+	ver, ok := importVersion(scheme, fields[len(fields)-1])
+	if !ok {
+		return "", "", fmt.Errorf("invalid version: %q", ver)
+	}
+	return ver, date, nil
+}
+
+// importTowncrierNews imports reStructuredText news files generated by
+// towncrier, whose release titles consist of an optional project name,
+// a version and an optional date, e.g., "kc 1.2.0 (2020-01-02)". Releases are
+// divided into categories, such as "Features", each holding a bullet list.
+// A leading document title, such as "Changelog", is skipped, as long as it is
+// adorned differently than release titles.
+func importTowncrierNews(name string, r io.Reader, scheme versionScheme) (rs releases, err error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		rel       *release
+		category  string
+		item      = -1 // the index of the current change, if continued
+		releaseAt byte // the adornment of release titles
+		titleAt   byte // the adornment of a skipped document title
+		titleErr  error
+	)
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " \t")
+		trimmed := strings.TrimSpace(line)
+
+		// Section titles are underlined (and possibly overlined) by
+		// a repeated punctuation character.
+		_, isAdornment := rstAdornment(trimmed)
+		if trimmed != "" && !isAdornment && i+1 < len(lines) {
+			if c, ok := rstAdornment(lines[i+1]); ok {
+				i++
+				item = -1
+				if releaseAt != 0 && c != releaseAt {
+					category = trimmed
+					continue
+				}
+				// The title is on the line preceding its underline.
+				ver, date, err := towncrierTitle(scheme, trimmed)
+				if err != nil {
+					err = fmt.Errorf("%s:%d: %s", name, i, err)
+				}
+				switch {
+				case err != nil && releaseAt == 0 && titleErr == nil:
+					// Skip a leading document title, such as "Changelog",
+					// unless it turns out to be adorned like release titles.
+					titleErr, titleAt = err, c
+					continue
+				case err != nil:
+					return nil, err
+				case releaseAt == 0 && titleErr != nil && c == titleAt:
+					return nil, titleErr
+				}
+				releaseAt = c
+				rel, category = newRelease(ver, date), ""
+				rs = append(rs, rel)
+				continue
+			}
+		}
+		switch {
+		case rel == nil, trimmed == "", isAdornment:
+			item = -1
+		case strings.HasPrefix(trimmed, ".."):
+			// Comments, such as ".. towncrier release notes start".
+			item = -1
+		case strings.HasPrefix(line, "- "), strings.HasPrefix(line, "* "):
+			rel.pushChange(category, line[2:])
+			item = len(rel.changes.get(category)) - 1
+		case item >= 0 && line != trimmed:
+			appendChange(rel, category, item, trimmed)
+		default:
+			rel.note = joinNote(rel.note, trimmed, strings.TrimSpace(lines[i-1]) == "")
+		}
+	}
+	if rs.empty() && titleErr != nil {
+		return nil, titleErr
+	}
+	return rs, nil
+}
+
+// githubRelease is a release as returned by the GitHub releases API.
+type githubRelease struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Body        string `json:"body"`
+	Draft       bool   `json:"draft"`
+	HTMLURL     string `json:"html_url"`
+	CreatedAt   string `json:"created_at"`
+	PublishedAt string `json:"published_at"`
+}
+
+var (
+	reMarkdownHeading  = regexp.MustCompile(`^#{1,6}\s+(.*?)[\s#]*$`)
+	reMarkdownListItem = regexp.MustCompile(`^[-*+]\s+(.*)$`)
+)
+
+// importGitHubReleases imports a JSON array of releases, as returned by the
+// GitHub releases API. Drafts are skipped. The Markdown body of each release
+// is divided into categories by its headings, while its top-level list items
+// are the changes and anything else makes up the release note.
+func importGitHubReleases(name string, r io.Reader, scheme versionScheme) (rs releases, err error) {
+	var grs []githubRelease
+	if err := json.NewDecoder(r).Decode(&grs); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, err)
+	}
+	for _, gr := range grs {
+		if gr.Draft {
+			continue
+		}
+		ver, ok := importVersion(scheme, gr.TagName)
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid version: %q", name, gr.TagName)
+		}
+		rel := &release{version: ver, link: gr.HTMLURL}
+		for _, ts := range []string{gr.PublishedAt, gr.CreatedAt} {
+			if t, err := time.Parse(time.RFC3339, ts); err == nil {
+				rel.date = truncateDay(t)
+				break
+			}
+		}
+		var (
+			category string
+			item     = -1
+			para     bool
+		)
+		lines, _ := readLines(strings.NewReader(gr.Body))
+		for _, line := range lines {
+			line = strings.TrimRight(line, " \t")
+			trimmed := strings.TrimSpace(line)
+			if m := reMarkdownHeading.FindStringSubmatch(line); m != nil {
+				category, item, para = m[1], -1, true
+				continue
+			}
+			switch m := reMarkdownListItem.FindStringSubmatch(line); {
+			case trimmed == "":
+				item, para = -1, true
+			case m != nil:
+				rel.pushChange(category, m[1])
+				item = len(rel.changes.get(category)) - 1
+			case item >= 0 && line != trimmed:
+				appendChange(rel, category, item, trimmed)
+			default:
+				rel.note = joinNote(rel.note, trimmed, para)
+				para = false
+			}
+		}
+		rs = append(rs, rel)
+	}
+	return rs, nil
+}
+
+var (
+	reDebianEntry      = regexp.MustCompile(`^\S+ \(([^)]+)\)`)
+	reDebianChange     = regexp.MustCompile(`^\s+\*\s+(.*)$`)
+	reDebianMaintainer = regexp.MustCompile(`^\s+\[.*\]$`)
+	reDebianTrailer    = regexp.MustCompile(`^ -- .*?  (.+)$`)
+)
+
+const debianDate = "Mon, _2 Jan 2006 15:04:05 -0700"
+
+// importDebianChangelog imports debian/changelog files. Entries are imported
+// as releases of their upstream version, i.e., without epoch and Debian
+// revision, and with the first "~" turned back into "-", so the entries of
+// several revisions make up a single release, which is dated by the latest of
+// them.
+func importDebianChangelog(name string, r io.Reader, scheme versionScheme) (rs releases, err error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		rel  *release
+		item = -1
+	)
+	for i, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if m := reDebianEntry.FindStringSubmatch(line); m != nil {
+			ver := m[1]
+			if i := strings.Index(ver, ":"); i >= 0 {
+				ver = ver[i+1:]
+			}
+			if i := strings.LastIndex(ver, "-"); i >= 0 {
+				ver = ver[:i]
+			}
+			// Upstream pre-releases sort before releases via "~", e.g.,
+			// 1.0.0~rc.1, which is how --export debian writes them.
+			ver = strings.Replace(ver, "~", "-", 1)
+			ver, ok := importVersion(scheme, ver)
+			if !ok {
+				return nil, fmt.Errorf("%s:%d: invalid version: %q", name, i+1, m[1])
+			}
+			if rel = rs.get(ver); rel == nil {
+				rel = &release{version: ver}
+				rs = append(rs, rel)
+			}
+			item = -1
+			continue
+		}
+		if rel == nil {
+			continue
+		}
+		switch {
+		case reDebianTrailer.MatchString(line):
+			date := reDebianTrailer.FindStringSubmatch(line)[1]
+			t, err := time.Parse(debianDate, date)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid date: %q", name, i+1, date)
+			}
+			if t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC); t.After(rel.date) {
+				rel.date = t
+			}
+			item = -1
+		case reDebianChange.MatchString(line):
+			rel.pushChange(keyUnlabeled, reDebianChange.FindStringSubmatch(line)[1])
+			item = len(rel.changes.get(keyUnlabeled)) - 1
+		case reDebianMaintainer.MatchString(line), strings.TrimSpace(line) == "":
+			item = -1
+		case item >= 0:
+			appendChange(rel, keyUnlabeled, item, strings.TrimSpace(line))
+		}
+	}
+	return rs, nil
+}
+
+func readLines(r io.Reader) (lines []string, err error) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		lines = append(lines, strings.TrimSuffix(s.Text(), "\r"))
+	}
+	return lines, s.Err()
+}
+
+// appendChange appends text to the i-th change labeled label, which spans
+// several lines in the source format.
+func appendChange(rel *release, label string, i int, text string) {
+	rel.changes.group(label).changes[i] += " " + text
+}
+
+// joinNote appends line to note, starting a new paragraph if para is set.
+func joinNote(note, line string, para bool) string {
+	switch {
+	case note == "":
+		return line
+	case para:
+		return note + "\n\n" + line
+	default:
+		return note + "\n" + line
+	}
+}
+
+// defaultImportLabels maps common categories of the import formats onto the
+// default change labels. Categories that map onto an empty label are dropped.
+var defaultImportLabels = map[string]string{
+	"features":                  "Added",
+	"feature":                   "Added",
+	"new features":              "Added",
+	"bugfixes":                  "Fixed",
+	"bugfix":                    "Fixed",
+	"bug fixes":                 "Fixed",
+	"fixes":                     "Fixed",
+	"deprecations and removals": "Removed",
+	"removals":                  "Removed",
+	"removal":                   "Removed",
+	"deprecations":              "Deprecated",
+	"deprecation":               "Deprecated",
+	"improved documentation":    "Changed",
+	"documentation":             "Changed",
+	"doc":                       "Changed",
+	"misc":                      "Changed",
+	"improvements":              "Changed",
+	"breaking changes":          "Changed",
+	"what's changed":            "Changed",
+	"new contributors":          "",
+	"trivial/internal changes":  "",
+}
+
+// importLabels relabels the changes of rel, which are labeled by the
+// categories of an import format, according to the import.labels table, the
+// default mapping and the change labels.
+func (c *config) importLabels(rel *release) error {
+	var changes changeSet
+	for _, g := range rel.changes {
+		label, ok := keyUnlabeled, true
+		if g.label != keyUnlabeled && len(c.Changes.Labels) > 0 {
+			var err error
+			if label, ok, err = c.importLabel(g.label); err != nil {
+				return fmt.Errorf("%s: %s", rel.version, err)
+			}
+		}
+		if !ok {
+			continue
+		}
+		dst := changes.group(label)
+		if dst == nil {
+			dst = &changeGroup{label: label}
+			changes = append(changes, dst)
+		}
+		dst.changes = append(dst.changes, g.changes...)
+	}
+	rel.changes = changes
+	return nil
+}
+
+// importLabel returns the change label for category and whether changes of
+// the category should be imported at all.
+func (c *config) importLabel(category string) (string, bool, error) {
+	var (
+		key    = strings.ToLower(category)
+		label  string
+		mapped bool
+	)
+	for _, labels := range []map[string]string{c.Import.Labels, defaultImportLabels} {
+		for name, l := range labels {
+			if strings.ToLower(name) == key {
+				label, mapped = l, true
+				break
+			}
+		}
+		if mapped {
+			break
+		}
+	}
+	switch {
+	case mapped && label == "":
+		return "", false, nil
+	case mapped:
+		name, ok := c.label(label)
+		if !ok {
+			return "", false, fmt.Errorf("category %q maps to unknown change label: %q", category, label)
+		}
+		return name, true, nil
+	}
+	name, ok := c.label(category)
+	if !ok {
+		return "", false, fmt.Errorf("unknown category: %q (map it via import.labels)", category)
+	}
+	return name, true, nil
+}
+
+// sameRelease reports whether a and b have the same date and changes.
+func sameRelease(a, b *release) bool {
+	if !a.date.Equal(b.date) || a.changeCount() != b.changeCount() {
+		return false
+	}
+	for _, g := range a.changes {
+		other := b.changes.get(g.label)
+		if len(other) != len(g.changes) {
+			return false
+		}
+		for i, ch := range g.changes {
+			if other[i] != ch {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// mergeImported adds those of rs that are not part of l yet. Releases that are, but
+// differ from the existing ones, are returned as conflicts, since the
+// existing releases are kept as is.
+func (l *changelog) mergeImported(rs releases) (added int, conflicts releases) {
+	for _, rel := range rs {
+		switch existing := l.get(rel.version); {
+		case existing == nil:
+			l.append(rel)
+			added++
+		case !sameRelease(existing, rel):
+			conflicts = append(conflicts, rel)
+		}
+	}
+	if added > 0 {
+		l.sort()
+	}
+	return
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImport(t *testing.T) {
+	date := func(s string) time.Time {
+		t, _ := time.Parse(iso8601, s)
+		return t
+	}
+	for _, test := range []struct {
+		name   string
+		format string
+		input  string
+		exp    releases
+		err    string
+	}{
+		{
+			name:   "towncrier",
+			format: importTowncrier,
+			input: `.. towncrier release notes start
+
+kc 1.1.0 (2020-01-02)
+=====================
+
+Features
+--------
+
+- Add a thing. (#1)
+- Wrapped
+  line.
+
+Bugfixes
+--------
+
+- Fix. (#2)
+
+
+1.0.0
+=====
+
+No significant changes.
+`,
+			exp: releases{
+				{
+					version: "1.1.0",
+					date:    date("2020-01-02"),
+					changes: changeSet{
+						{label: "Features", changes: []string{"Add a thing. (#1)", "Wrapped line."}},
+						{label: "Bugfixes", changes: []string{"Fix. (#2)"}},
+					},
+				},
+				{
+					version: "1.0.0",
+					note:    "No significant changes.",
+				},
+			},
+		},
+		{
+			name:   "towncrier invalid version",
+			format: importTowncrier,
+			input:  "Release one\n===========\n",
+			err:    `NEWS:1: invalid version: "one"`,
+		},
+		{
+			name:   "towncrier date-only title",
+			format: importTowncrier,
+			input:  "(2020-01-02)\n============\n",
+			err:    "NEWS:1: invalid release title",
+		},
+		{
+			name:   "towncrier date-only title after release",
+			format: importTowncrier,
+			input:  "1.0.0\n=====\n\n(2020-01-02)\n============\n",
+			err:    "NEWS:4: invalid release title",
+		},
+		{
+			name:   "towncrier document title",
+			format: importTowncrier,
+			input: `Changelog
+=========
+
+Versions follow Calendar Versioning.
+
+.. towncrier release notes start
+
+25.1.0 (2025-01-25)
+-------------------
+
+Changes
+^^^^^^^
+
+- a
+
+24.2.0 (2024-08-06)
+-------------------
+
+- b
+`,
+			exp: releases{
+				{
+					version: "25.1.0",
+					date:    date("2025-01-25"),
+					changes: changeSet{{label: "Changes", changes: []string{"a"}}},
+				},
+				{
+					version: "24.2.0",
+					date:    date("2024-08-06"),
+					changes: changeSet{{label: keyUnlabeled, changes: []string{"b"}}},
+				},
+			},
+		},
+		{
+			name:   "towncrier document title adorned like releases",
+			format: importTowncrier,
+			input:  "Changelog\n=========\n\n1.0.0\n=====\n",
+			err:    `NEWS:1: invalid version: "Changelog"`,
+		},
+		{
+			name:   "github",
+			format: importGitHub,
+			input: `[
+				{"tag_name": "v1.1.0", "draft": true},
+				{
+					"tag_name": "v1.0.0",
+					"html_url": "https://example.com/v1.0.0",
+					"published_at": "2020-01-02T23:00:00Z",
+					"body": "Intro.\r\n\r\n## What's Changed\r\n* a\r\n  wrapped\r\n* b\r\n\r\nOutro."
+				}
+			]`,
+			exp: releases{
+				{
+					version: "1.0.0",
+					date:    date("2020-01-02"),
+					link:    "https://example.com/v1.0.0",
+					note:    "Intro.\n\nOutro.",
+					changes: changeSet{
+						{label: "What's Changed", changes: []string{"a wrapped", "b"}},
+					},
+				},
+			},
+		},
+		{
+			name:   "debian",
+			format: importDebian,
+			input: `kc (1:1.1.0-2) unstable; urgency=medium
+
+  * Rebuild.
+
+ -- Jane Doe <jane@example.com>  Tue, 03 Mar 2020 23:00:00 -0500
+
+kc (1.1.0-1) unstable; urgency=medium
+
+  [ John Doe ]
+  * New upstream
+    release.
+
+ -- John Doe <john@example.com>  Mon,  2 Mar 2020 10:00:00 +0100
+
+kc (1.0) unstable; urgency=low
+
+  * Initial release.
+
+ -- John Doe <john@example.com>  Mon,  2 Mar 2020 10:00:00 +0100
+`,
+			err: `NEWS:15: invalid version: "1.0"`,
+		},
+		{
+			name:   "debian revisions",
+			format: importDebian,
+			input: `kc (1:1.1.0-2) unstable; urgency=medium
+
+  * Rebuild.
+
+ -- Jane Doe <jane@example.com>  Tue, 03 Mar 2020 23:00:00 -0500
+
+kc (1.1.0-1) unstable; urgency=medium
+
+  [ John Doe ]
+  * New upstream
+    release.
+
+ -- John Doe <john@example.com>  Mon,  2 Mar 2020 10:00:00 +0100
+`,
+			exp: releases{
+				{
+					version: "1.1.0",
+					date:    date("2020-03-03"),
+					changes: changeSet{
+						{label: keyUnlabeled, changes: []string{"Rebuild.", "New upstream release."}},
+					},
+				},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			rs, err := importers[test.format]("NEWS", strings.NewReader(test.input), semverScheme{})
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("expected error %q, got %v", test.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rs, test.exp) {
+				t.Errorf("expected:\n%s\ngot:\n%s", dumpReleases(test.exp), dumpReleases(rs))
+			}
+		})
+	}
+}
+
+func dumpReleases(rs releases) string {
+	var b strings.Builder
+	for _, rel := range rs {
+		b.WriteString(rel.version + " " + rel.date.Format(iso8601) + " " + rel.link + "\n")
+		b.WriteString("  note: " + rel.note + "\n")
+		for _, g := range rel.changes {
+			b.WriteString("  " + g.label + ": " + strings.Join(g.changes, " | ") + "\n")
+		}
+	}
+	return b.String()
+}
+
+func TestImportLabels(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Import.Labels = map[string]string{"Misc": "", "Enhancements": "added"}
+	rel := &release{
+		version: "1.0.0",
+		changes: changeSet{
+			{label: "Features", changes: []string{"a"}},
+			{label: "misc", changes: []string{"b"}},
+			{label: "Enhancements", changes: []string{"c"}},
+			{label: "security", changes: []string{"d"}},
+		},
+	}
+	if err := cfg.importLabels(rel); err != nil {
+		t.Fatal(err)
+	}
+	exp := changeSet{
+		{label: "Added", changes: []string{"a", "c"}},
+		{label: "Security", changes: []string{"d"}},
+	}
+	if !reflect.DeepEqual(rel.changes, exp) {
+		t.Errorf("expected %v, got %v", exp, rel.changes)
+	}
+
+	rel.changes = changeSet{{label: "Chores", changes: []string{"e"}}}
+	err := cfg.importLabels(rel)
+	if exp := `1.0.0: unknown category: "Chores" (map it via import.labels)`; err == nil || err.Error() != exp {
+		t.Errorf("expected error %q, got %v", exp, err)
+	}
+}
+
+func TestImportExportedDebian(t *testing.T) {
+	doc := exportDoc{
+		changelogDoc: changelogDoc{
+			Releases: []releaseDoc{
+				{
+					Version: "1.0.0-rc.1",
+					Date:    "2020-03-02",
+					Changes: []changesDoc{{Label: keyUnlabeled, Items: []string{"a"}}},
+				},
+			},
+		},
+		Packaging: packagingDoc{
+			Name:         "kc",
+			Maintainer:   "John Doe",
+			Email:        "john@example.com",
+			Distribution: "unstable",
+			Urgency:      "medium",
+			Revision:     "1",
+		},
+		scheme: semverScheme{},
+	}
+	var b strings.Builder
+	if err := export(&b, exportDebian, exportTemplates[exportDebian], doc); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(b.String(), "kc (1.0.0~rc.1-1)") {
+		t.Fatalf("unexpected export:\n%s", b.String())
+	}
+	rs, err := importDebianChangelog("changelog", strings.NewReader(b.String()), semverScheme{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	date, _ := time.Parse(iso8601, "2020-03-02")
+	exp := releases{
+		{
+			version: "1.0.0-rc.1",
+			date:    date,
+			changes: changeSet{{label: keyUnlabeled, changes: []string{"a"}}},
+		},
+	}
+	if !reflect.DeepEqual(rs, exp) {
+		t.Errorf("expected:\n%s\ngot:\n%s", dumpReleases(exp), dumpReleases(rs))
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const lockSuffix = ".kc.lock"
+
+const (
+	defaultLockTimeout = 10 * time.Second
+	lockPollInterval   = 50 * time.Millisecond
+)
+
+// errLocked is returned by tryLock if the lock is held by another process.
+var errLocked = errors.New("file locked")
+
+// lockFile acquires an exclusive advisory lock on path, which guards the
+// load-modify-save cycle of concurrent kc processes. The lock is represented
+// by path+lockSuffix. If the lock is held by another process, contended is
+// called once and lockFile retries until timeout elapses.
+func lockFile(path string, timeout time.Duration, contended func()) (*fileLock, error) {
+	var (
+		lockPath = path + lockSuffix
+		deadline = time.Now().Add(timeout)
+		waiting  bool
+	)
+	for {
+		l, err := tryLock(lockPath)
+		if err != errLocked {
+			return l, err
+		}
+		if !waiting {
+			waiting = true
+			contended()
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("timed out after %s waiting for lock: %s", timeout, lockPath)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+func (c *config) lockTimeout() (time.Duration, error) {
+	if c.Files.LockTimeout == "" {
+		return defaultLockTimeout, nil
+	}
+	d, err := time.ParseDuration(c.Files.LockTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid lock timeout: %q", c.Files.LockTimeout)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("negative lock timeout: %q", c.Files.LockTimeout)
+	}
+	return d, nil
+}
@@ -0,0 +1,31 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package main
+
+import "os"
+
+// fileLock falls back to an exclusively created lock file on systems without
+// flock(2). A lock file left behind by a crashed process must be removed
+// manually.
+type fileLock struct {
+	path string
+}
+
+func tryLock(path string) (*fileLock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	return &fileLock{path: path}, nil
+}
+
+func (l *fileLock) unlock() error {
+	return os.Remove(l.path)
+}
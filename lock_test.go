@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "CHANGELOG.md")
+
+	var contended int
+	wait := func() { contended++ }
+
+	l, err := lockFile(path, 0, wait)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockFile(path, 2*lockPollInterval, wait); err == nil {
+		t.Fatal("expected lock to be held")
+	}
+	if contended != 1 {
+		t.Errorf("expected contended to be called once, got %d", contended)
+	}
+
+	// The lock is handed over once released.
+	done := make(chan error)
+	go func() {
+		l, err := lockFile(path, time.Minute, wait)
+		if err == nil {
+			err = l.unlock()
+		}
+		done <- err
+	}()
+	time.Sleep(2 * lockPollInterval)
+	if err := l.unlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if pathExists(path + lockSuffix) {
+		t.Errorf("lock file not removed: %s", path+lockSuffix)
+	}
+}
+
+func TestInvokeLocked(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer cd(t, cd(t, dir))
+	files := map[string]string{
+		"CHANGELOG.md": "# Changelog\n\n## 0.1.0 - 2020-01-01\n\n### Added\n\n- a\n",
+		".kcrc":        "[files]\nlock-timeout = \"0s\"\n",
+	}
+	for name, text := range files {
+		if err := ioutil.WriteFile(name, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l, err := lockFile("CHANGELOG.md", 0, func() {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.unlock()
+
+	for _, test := range []struct {
+		args   []string
+		locked bool
+	}{
+		{args: []string{"--list"}},
+		{args: []string{"--show", "0.1.0"}},
+		{args: []string{"--export", "html"}},
+		{args: []string{"--sort"}, locked: true},
+		{args: []string{"--release", "patch"}, locked: true},
+	} {
+		inv := invocation{
+			stdin:  new(bytes.Buffer),
+			stdout: new(bytes.Buffer),
+			stderr: new(bytes.Buffer),
+		}
+		err := inv.invoke(test.args)
+		switch {
+		case test.locked && (err == nil || !strings.Contains(err.Error(), "waiting for lock")):
+			t.Errorf("%v: expected a lock timeout, got %v", test.args, err)
+		case !test.locked && err != nil:
+			t.Errorf("%v: unexpected error: %v", test.args, err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+type fileLock struct {
+	path string
+	f    *os.File
+}
+
+func tryLock(path string) (*fileLock, error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+			f.Close()
+			if err == syscall.EWOULDBLOCK {
+				return nil, errLocked
+			}
+			return nil, err
+		}
+		// The previous holder removes the lock file upon unlocking, so we may
+		// have locked a file that no longer exists. If so, start over.
+		fi, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if cur, err := os.Stat(path); err == nil && os.SameFile(fi, cur) {
+			return &fileLock{path: path, f: f}, nil
+		}
+		f.Close()
+	}
+}
+
+func (l *fileLock) unlock() error {
+	// Remove the file while still holding the lock; see tryLock.
+	err := os.Remove(l.path)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -27,10 +27,62 @@ type config struct {
 	// found in the input text, or if none can be generated from templates.
 	writeReleaseLinks bool
 
+	// versioning is set up according to the versioning table by
+	// initVersioning.
+	versioning versionScheme
+
+	// projectName and changelog are set if the config is that of a project
+	// listed in the changelogs table.
+	projectName string
+	changelog   string
+
 	Links   map[string]string `toml:"links,omitempty"`
 	Changes struct {
 		Labels []string `toml:"labels,omitempty"`
 	} `toml:"changes,omitempty"`
+	Commits struct {
+		Types map[string]string `toml:"types,omitempty"`
+	} `toml:"commits,omitempty"`
+	Release struct {
+		Bumps    map[string]string `toml:"bumps,omitempty"`
+		Breaking string            `toml:"breaking,omitempty"`
+	} `toml:"release,omitempty"`
+	Files struct {
+		Backup      bool   `toml:"backup,omitempty"`
+		LockTimeout string `toml:"lock-timeout,omitempty"`
+	} `toml:"files,omitempty"`
+	Fragments struct {
+		Dir string `toml:"dir,omitempty"`
+	} `toml:"fragments,omitempty"`
+	Check struct {
+		Disable []string `toml:"disable,omitempty"`
+	} `toml:"check,omitempty"`
+	Versioning struct {
+		Scheme string `toml:"scheme,omitempty"`
+		Format string `toml:"format,omitempty"`
+		Prefix string `toml:"prefix,omitempty"`
+		Tag    string `toml:"tag,omitempty"`
+	} `toml:"versioning,omitempty"`
+	Export struct {
+		Templates map[string]string `toml:"templates,omitempty"`
+	} `toml:"export,omitempty"`
+	Import struct {
+		Labels map[string]string `toml:"labels,omitempty"`
+	} `toml:"import,omitempty"`
+	Packaging struct {
+		Name         string `toml:"name,omitempty"`
+		Maintainer   string `toml:"maintainer,omitempty"`
+		Email        string `toml:"email,omitempty"`
+		Distribution string `toml:"distribution,omitempty"`
+		Urgency      string `toml:"urgency,omitempty"`
+		Revision     string `toml:"revision,omitempty"`
+	} `toml:"packaging,omitempty"`
+	Feed struct {
+		Title string `toml:"title,omitempty"`
+		ID    string `toml:"id,omitempty"`
+		URL   string `toml:"url,omitempty"`
+	} `toml:"feed,omitempty"`
+	Changelogs map[string]*project `toml:"changelogs,omitempty"`
 }
 
 func newConfig() *config {
@@ -89,6 +141,83 @@ func (a *config) merge(b *config) error {
 	if b.Changes.Labels != nil {
 		a.Changes.Labels = b.Changes.Labels
 	}
+	if b.Commits.Types != nil {
+		a.Commits.Types = b.Commits.Types
+	}
+	if b.Release.Bumps != nil {
+		a.Release.Bumps = b.Release.Bumps
+	}
+	if b.Release.Breaking != "" {
+		a.Release.Breaking = b.Release.Breaking
+	}
+	if b.Files.Backup {
+		a.Files.Backup = b.Files.Backup
+	}
+	if b.Files.LockTimeout != "" {
+		a.Files.LockTimeout = b.Files.LockTimeout
+	}
+	if b.Fragments.Dir != "" {
+		a.Fragments.Dir = b.Fragments.Dir
+	}
+	if b.Check.Disable != nil {
+		a.Check.Disable = b.Check.Disable
+	}
+	if b.Versioning.Scheme != "" {
+		a.Versioning.Scheme = b.Versioning.Scheme
+	}
+	if b.Versioning.Format != "" {
+		a.Versioning.Format = b.Versioning.Format
+	}
+	if b.Versioning.Prefix != "" {
+		a.Versioning.Prefix = b.Versioning.Prefix
+	}
+	if b.Versioning.Tag != "" {
+		a.Versioning.Tag = b.Versioning.Tag
+	}
+	if a.Export.Templates == nil {
+		a.Export.Templates = b.Export.Templates
+	} else {
+		for format, path := range b.Export.Templates {
+			a.Export.Templates[format] = path
+		}
+	}
+	if a.Import.Labels == nil {
+		a.Import.Labels = b.Import.Labels
+	} else {
+		for category, label := range b.Import.Labels {
+			a.Import.Labels[category] = label
+		}
+	}
+	if b.Packaging.Name != "" {
+		a.Packaging.Name = b.Packaging.Name
+	}
+	if b.Packaging.Maintainer != "" {
+		a.Packaging.Maintainer = b.Packaging.Maintainer
+	}
+	if b.Packaging.Email != "" {
+		a.Packaging.Email = b.Packaging.Email
+	}
+	if b.Packaging.Distribution != "" {
+		a.Packaging.Distribution = b.Packaging.Distribution
+	}
+	if b.Packaging.Urgency != "" {
+		a.Packaging.Urgency = b.Packaging.Urgency
+	}
+	if b.Packaging.Revision != "" {
+		a.Packaging.Revision = b.Packaging.Revision
+	}
+	if b.Feed.Title != "" {
+		a.Feed.Title = b.Feed.Title
+	}
+	if b.Feed.ID != "" {
+		a.Feed.ID = b.Feed.ID
+	}
+	if b.Feed.URL != "" {
+		a.Feed.URL = b.Feed.URL
+	}
+	if b.Changelogs != nil {
+		a.Changelogs = b.Changelogs
+	}
 	return nil
 }
 
@@ -119,9 +248,25 @@ type changelog struct {
 	path   string
 	title  string
 	header string
+	scheme versionScheme
 	releases
 }
 
+func (l *changelog) versioning() versionScheme {
+	if l.scheme == nil {
+		return semverScheme{}
+	}
+	return l.scheme
+}
+
+func (l *changelog) sort() {
+	l.releases.sort(l.versioning())
+}
+
+func (l *changelog) match(pattern string) releases {
+	return l.releases.match(l.versioning(), pattern)
+}
+
 func (l *changelog) release(ver string, date time.Time) (rel *release) {
 	if rel = l.unreleased(); rel != nil {
 		rel.version = ver
@@ -150,7 +295,7 @@ func (l *changelog) validate(cfg *config) error {
 }
 
 func (l *changelog) save(cfg *config) error {
-	return write(l.path, os.O_TRUNC|os.O_CREATE, func(f *os.File) error {
+	return writeAtomic(l.path, cfg.Files.Backup, func(f *os.File) error {
 		return l.write(f, cfg)
 	})
 }
@@ -187,6 +332,16 @@ func (rs releases) has(ver string) bool {
 	return rs.get(ver) != nil
 }
 
+// latest returns the most recent release, skipping the Unreleased section.
+func (rs releases) latest() *release {
+	for _, r := range rs {
+		if !r.unreleased() {
+			return r
+		}
+	}
+	return nil
+}
+
 func (rs releases) head() *release {
 	return rs.at(0)
 }
@@ -213,37 +368,21 @@ func (rs releases) empty() bool {
 	return len(rs) == 0
 }
 
-func (rs releases) sort() {
+func (rs releases) sort(scheme versionScheme) {
 	var s int
 	// If the Unreleased section is at the top, it stays there. If found lower
 	// on the stack, it always takes precedence over numeric version strings.
 	if rs.head().unreleased() {
 		s++
 	}
-	sort.Slice(rs[s:], func(i, j int) bool {
-		var (
-			a  = rs[s+i].version
-			b  = rs[s+j].version
-			ma = reVersion.FindStringSubmatch(a)
-			mb = reVersion.FindStringSubmatch(b)
-		)
-		if len(ma) == 0 || len(mb) == 0 {
-			// Sort lexicographically if the version strings do not resemble
-			// semver, i.e., we're dealing with "unreleased".
+	sort.SliceStable(rs[s:], func(i, j int) bool {
+		a, b := rs[s+i].version, rs[s+j].version
+		if !scheme.valid(a) || !scheme.valid(b) {
+			// Sort lexicographically if the version strings do not adhere
+			// to the scheme, i.e., we're dealing with "unreleased".
 			return a > b
 		}
-		for i := 0; i < reVersion.NumSubexp(); i++ {
-			va, _ := strconv.Atoi(ma[i+1])
-			vb, _ := strconv.Atoi(mb[i+1])
-			switch {
-			case va > vb:
-				return true
-			case va < vb:
-				return false
-			}
-
-		}
-		return true
+		return scheme.compare(a, b) > 0
 	})
 }
 
@@ -289,8 +428,8 @@ func (rs releases) each(fn func(*release)) {
 	}
 }
 
-func (rs releases) match(pattern string) (res releases) {
-	return rs.filter(func(r *release) bool { return r.match(pattern) })
+func (rs releases) match(scheme versionScheme, pattern string) (res releases) {
+	return rs.filter(func(r *release) bool { return r.match(scheme, pattern) })
 }
 
 func (rs releases) filter(fn func(*release) bool) (res releases) {
@@ -307,7 +446,67 @@ type release struct {
 	date    time.Time
 	link    string
 	note    string
-	changes map[string][]string
+	yanked  bool
+	changes changeSet
+}
+
+// changeSet holds the changes of a release grouped by label. Groups are kept
+// in the order in which they are introduced.
+type changeSet []*changeGroup
+
+type changeGroup struct {
+	label   string
+	changes []string
+}
+
+func (cs changeSet) group(label string) *changeGroup {
+	for _, g := range cs {
+		if g.label == label {
+			return g
+		}
+	}
+	return nil
+}
+
+func (cs changeSet) get(label string) []string {
+	if g := cs.group(label); g != nil {
+		return g.changes
+	}
+	return nil
+}
+
+func (cs changeSet) has(label string) bool {
+	return cs.group(label) != nil
+}
+
+func (cs changeSet) labels() (labels []string) {
+	for _, g := range cs {
+		labels = append(labels, g.label)
+	}
+	return
+}
+
+// sorted returns a copy of cs ordered according to labels. Groups whose label
+// is not found in labels retain their relative order and go last. Unlabeled
+// changes always go first, since they cannot follow a label heading.
+func (cs changeSet) sorted(labels []string) changeSet {
+	index := func(label string) int {
+		if label == keyUnlabeled {
+			return -1
+		}
+		for i, name := range labels {
+			if strings.EqualFold(name, label) {
+				return i
+			}
+		}
+		return len(labels)
+	}
+	res := make(changeSet, len(cs))
+	copy(res, cs)
+	sort.SliceStable(res, func(i, j int) bool {
+		return index(res[i].label) < index(res[j].label)
+	})
+	return res
 }
 
 var dateSeparator = strings.NewReplacer(
@@ -337,11 +536,22 @@ func (rel *release) is(ver string) bool {
 	return strings.EqualFold(rel.version, ver)
 }
 
-func (rel *release) match(pattern string) bool {
-	if isGlob(pattern) {
+func (rel *release) match(scheme versionScheme, pattern string) bool {
+	switch {
+	case scheme.valid(pattern), isRange(pattern):
+		return rel.matchVersion(scheme, pattern)
+	case isGlob(pattern):
 		return rel.matchGlob(pattern)
+	default:
+		return rel.matchPrefix(pattern)
 	}
-	return rel.matchPrefix(pattern)
+}
+
+// matchVersion reports whether the release version has the same precedence
+// as ver, so that 1.0.0 matches 1.0.0+build, but not 1.0.0-rc.1 or 1.0.10, or
+// whether it is within range, if ver is a version range.
+func (rel *release) matchVersion(scheme versionScheme, ver string) bool {
+	return len(matchVersions(scheme, []string{rel.version}, ver)) > 0
 }
 
 func (rel *release) matchGlob(pattern string) bool {
@@ -354,21 +564,40 @@ func (rel *release) matchPrefix(pattern string) bool {
 }
 
 func (rel *release) changeLabels() []string {
-	return keys(rel.changes)
+	return rel.changes.labels()
 }
 
 func (rel *release) changeCount() (n int) {
-	for _, changes := range rel.changes {
-		n += len(changes)
+	for _, g := range rel.changes {
+		n += len(g.changes)
 	}
 	return
 }
 
 func (rel *release) withChangeList(typ string, do func([]string) []string) {
-	if rel.changes == nil {
-		rel.changes = make(map[string][]string)
+	g := rel.changes.group(typ)
+	if g == nil {
+		g = &changeGroup{label: typ}
+		rel.changes = append(rel.changes, g)
+	}
+	g.changes = do(g.changes)
+}
+
+// normalizeChange returns text as a change entry, i.e., without surrounding
+// whitespace and without a leading list bullet, which is implied. Changes that
+// are added via the command line and via fragments are normalized alike, so
+// that a blank bullet makes for no change at all.
+func normalizeChange(text string) string {
+	text = strings.TrimSpace(text)
+	for _, bullet := range []string{"-", "*", "+"} {
+		if text == bullet {
+			return ""
+		}
+		if strings.HasPrefix(text, bullet+" ") {
+			return strings.TrimSpace(text[len(bullet):])
+		}
 	}
-	rel.changes[typ] = do(rel.changes[typ])
+	return text
 }
 
 func (rel *release) pushChange(typ, text string) {
@@ -397,9 +626,9 @@ func (rel *release) merge(other *release) {
 	default:
 		rel.note += "\n\n" + other.note
 	}
-	for typ, list := range other.changes {
-		for _, ch := range list {
-			rel.pushChange(typ, ch)
+	for _, g := range other.changes {
+		for _, ch := range g.changes {
+			rel.pushChange(g.label, ch)
 		}
 	}
 }
@@ -413,16 +642,26 @@ func (rel *release) String() string {
 
 func (rel *release) details() string {
 	n := rel.changeCount()
-	return fmt.Sprintf("%s (%d %s)", rel, n, pluralize("change", n))
+	s := fmt.Sprintf("%s (%d %s)", rel, n, pluralize("change", n))
+	if rel.yanked {
+		s += " " + yankedTag
+	}
+	return s
 }
 
+const yankedTag = "[YANKED]"
+
 var (
-	reVersion     = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)\S*?`)
 	reUnreleased  = regexp.MustCompile(`(?i:^\s*\[?unreleased\]?$)`)
-	reRelease     = regexp.MustCompile(`^\s*\[?(\d+\.\d+\.\d+\S*?)\]?(?:\s+-\s+(\d{4}[-\./]\d{2}[-\./]\d{2}))?$`)
 	reReleaseLink = regexp.MustCompile(`^\[([[:word:].-]+)\]:\s*(\S+)`)
 )
 
+// releaseRegexp returns a regular expression that matches release headings,
+// whose version strings match the version pattern.
+func releaseRegexp(version string) *regexp.Regexp {
+	return regexp.MustCompile(`^\s*\[?(` + version + `)\]?(?:\s+-\s+(\d{4}[-\./]\d{2}[-\./]\d{2}))?(\s+(?i:\[yanked\]))?$`)
+}
+
 const (
 	keyUnreleased     = "unreleased"
 	keyRelease        = "release"
@@ -440,6 +679,43 @@ type changelogParser struct {
 	log     *changelog
 	config  *config
 	mru     *release
+
+	reRelease *regexp.Regexp
+
+	// tolerant instructs the parser to accept common deviations from the
+	// canonical format: "*" bullets and links to non-existent releases, which
+	// are dropped.
+	tolerant bool
+
+	// The following are recorded for the benefit of the changelog checker
+	// and --stats.
+	errs     []lineError
+	headings []releaseHeading
+	links    []releaseLinkRef
+	items    []changeItem
+}
+
+type lineError struct {
+	line int
+	err  error
+}
+
+type releaseHeading struct {
+	line int
+	text string
+	rel  *release
+}
+
+type releaseLinkRef struct {
+	line    int
+	version string
+	link    string
+}
+
+// changeItem records the line on which a change of rel starts.
+type changeItem struct {
+	line int
+	rel  *release
 }
 
 type changelogPrefixParser struct {
@@ -449,8 +725,9 @@ type changelogPrefixParser struct {
 
 func newChangelogParser(name string, cfg *config) *changelogParser {
 	p := &changelogParser{
-		name:   name,
-		config: cfg,
+		name:      name,
+		config:    cfg,
+		reRelease: releaseRegexp(cfg.scheme().pattern()),
 	}
 	ignore := func(string) error { return nil }
 	p.rules = []*changelogPrefixParser{
@@ -465,9 +742,17 @@ func newChangelogParser(name string, cfg *config) *changelogParser {
 	return p
 }
 
+func newTolerantChangelogParser(name string, cfg *config) *changelogParser {
+	p := newChangelogParser(name, cfg)
+	p.tolerant = true
+	n := len(p.rules) - 1
+	p.rules = append(p.rules[:n:n], &changelogPrefixParser{"* ", p.parseUnlabeledChanges}, p.rules[n])
+	return p
+}
+
 func (p *changelogParser) parse(r io.Reader) (*changelog, error) {
 	p.scanner = bufio.NewScanner(r)
-	p.log = new(changelog)
+	p.log = &changelog{scheme: p.config.scheme()}
 	var errs []error
 	for p.scan() {
 		line := p.line()
@@ -484,7 +769,9 @@ func (p *changelogParser) parse(r io.Reader) (*changelog, error) {
 	if errs != nil {
 		var err parseError
 		err.wrap(errs...)
-		return nil, err
+		// Return the partially parsed changelog anyway, which the changelog
+		// checker makes use of.
+		return p.log, err
 	}
 	if err := p.scanner.Err(); err != nil {
 		return nil, ioError{err}
@@ -532,18 +819,26 @@ func (p *changelogParser) parseRelease(line string) error {
 			rel = newUnreleased()
 			p.log.prepend(rel)
 		}
-	case reRelease.MatchString(line):
-		fields := reRelease.FindStringSubmatch(line)[1:]
-		ver, date := fields[0], fields[1]
+	case p.reRelease.MatchString(line):
+		fields := p.reRelease.FindStringSubmatch(line)[1:]
+		ver, date, yanked := fields[0], fields[1], fields[2] != ""
 		rel = p.log.get(ver)
 		if rel == nil {
 			rel = newRelease(ver, date)
 			p.log.append(rel)
 		}
+		if yanked {
+			rel.yanked = true
+		}
 	}
 	if rel == nil {
 		return fmt.Errorf("invalid version string: %q", line)
 	}
+	p.headings = append(p.headings, releaseHeading{
+		line: p.lineNo,
+		text: line,
+		rel:  rel,
+	})
 	p.mru = rel
 	return p.parseReleaseNote(rel)
 }
@@ -560,7 +855,7 @@ LOOP:
 				return err
 			}
 			continue
-		case hasAnyPrefix(line, "#-+"):
+		case hasAnyPrefix(line, "#-+"), p.tolerant && strings.HasPrefix(line, "* "):
 			p.unscan()
 			break LOOP
 		}
@@ -578,6 +873,18 @@ LOOP:
 
 var errIncompatChanges = errors.New("unlabeled and labeled changes cannot coexist")
 
+type unknownLabelError string
+
+func (e unknownLabelError) Error() string {
+	return fmt.Sprintf("unknown change label: %q", string(e))
+}
+
+type orphanLinkError string
+
+func (e orphanLinkError) Error() string {
+	return fmt.Sprintf("release link (%s) is missing a corresponding version heading", string(e))
+}
+
 func (p *changelogParser) parseUnlabeledChanges(line string) error {
 	line = strings.TrimSpace(line[1:]) // - +
 	if line == "" {
@@ -589,12 +896,13 @@ func (p *changelogParser) parseUnlabeledChanges(line string) error {
 		// if no release heading precedes them.
 		return errors.New("change is missing a version heading")
 	}
-	for typ := range rel.changes {
+	for _, typ := range rel.changeLabels() {
 		if typ != keyUnlabeled {
 			return errIncompatChanges
 		}
 	}
 	rel.pushChange(keyUnlabeled, line)
+	p.items = append(p.items, changeItem{line: p.lineNo, rel: rel})
 	return p.parseChanges(rel, keyUnlabeled)
 }
 
@@ -609,13 +917,13 @@ func (p *changelogParser) parseLabeledChanges(line string) error {
 		// header if no release heading precedes them.
 		return errors.New("change label is missing a version heading")
 	}
-	if rel.changes[keyUnlabeled] != nil {
+	if rel.changes.has(keyUnlabeled) {
 		return errIncompatChanges
 	}
 	label, ok := p.config.label(line)
 	if !ok {
 		// Embed the current line number before skipping ahead.
-		err := p.err(fmt.Errorf("unknown change label: %q", line))
+		err := p.err(unknownLabelError(line))
 		p.skipUntil("[#")
 		return err
 	}
@@ -644,6 +952,7 @@ func (p *changelogParser) parseChanges(rel *release, label string) error {
 				continue
 			}
 			rel.pushChange(label, line)
+			p.items = append(p.items, changeItem{line: p.lineNo, rel: rel})
 		default:
 			rel.mergeChange(label, line)
 		}
@@ -655,9 +964,17 @@ func (p *changelogParser) parseReleaseLink(line string) error {
 	// NOTE: ensure callers check whether the line matches a reReleaseLink.
 	fields := reReleaseLink.FindStringSubmatch(line)[1:]
 	ver, link := fields[0], fields[1]
+	p.links = append(p.links, releaseLinkRef{
+		line:    p.lineNo,
+		version: ver,
+		link:    link,
+	})
 	rel := p.log.get(ver)
 	if rel == nil {
-		return fmt.Errorf("release link (%s) is missing a corresponding version heading", ver)
+		if p.tolerant {
+			return nil
+		}
+		return orphanLinkError(ver)
 	}
 	rel.link = link
 	return nil
@@ -708,6 +1025,7 @@ func (p *changelogParser) err(err error) error {
 		return err
 	}
 	if err != nil {
+		p.errs = append(p.errs, lineError{p.lineNo, err})
 		// TODO: maybe find a better way to detect if we're handling a one-off
 		// changelog (p.name == "") or an actual changelog file.
 		var (
@@ -799,43 +1117,10 @@ func (r *changelogRenderer) renderHeader(w io.Writer) {
 func (r *changelogRenderer) renderReleases(w io.Writer) {
 	for i, rel := range r.log.releases {
 		var (
-			tmpls        = r.config.Links
-			heading      = rel.version
-			link         = rel.link
-			isUnreleased = rel.unreleased()
-			isInitial    = i == len(r.log.releases)-1
-			prev         = r.log.at(i + 1)
+			heading = rel.version
+			link    = r.releaseLink(i)
 		)
 
-		// Regenerate link if possible.
-		switch {
-		case isUnreleased && isInitial:
-			tmpl := tmpls[keyUnreleased]
-			switch {
-			case placeholderPrevious.in(tmpl):
-				// Drop the Unreleased link if it is referencing a non-existent
-				// previous release.
-				link = ""
-			default:
-				// Otherwise, use the template (if any) and discard the
-				// original link.
-				link = tmpl
-			}
-		case isUnreleased:
-			if tmpl := tmpls[keyUnreleased]; tmpl != "" {
-				link = placeholderPrevious.interpolate(tmpl, prev.version)
-			}
-		case isInitial:
-			if tmpl := tmpls[keyInitialRelease]; tmpl != "" {
-				link = placeholderCurrent.interpolate(tmpl, rel.version)
-			}
-		default:
-			if tmpl := tmpls[keyRelease]; tmpl != "" {
-				link = placeholderPrevious.interpolate(tmpl, prev.version)
-				link = placeholderCurrent.interpolate(link, rel.version)
-			}
-		}
-
 		if r.config.writeReleaseLinks && link != "" {
 			heading = fmt.Sprintf("[%s]", heading)
 			r.refs = append(r.refs, rel.version, link)
@@ -843,6 +1128,9 @@ func (r *changelogRenderer) renderReleases(w io.Writer) {
 		if !rel.date.IsZero() {
 			heading += " - " + rel.date.Format(iso8601)
 		}
+		if rel.yanked {
+			heading += " " + yankedTag
+		}
 
 		r.renderSeparator(w)
 		r.renderLine(w, "## %s", heading)
@@ -850,10 +1138,51 @@ func (r *changelogRenderer) renderReleases(w io.Writer) {
 			r.renderSeparator(w)
 			r.renderLine(w, r.interpolateMentions(rel.note))
 		}
-		for _, label := range rel.changeLabels() {
-			r.renderChanges(w, label, rel.changes[label])
+		for _, g := range rel.changes.sorted(r.config.Changes.Labels) {
+			r.renderChanges(w, g.label, g.changes)
+		}
+	}
+}
+
+// releaseLink returns the link for the i-th release, which is regenerated from
+// the link templates if possible.
+func (r *changelogRenderer) releaseLink(i int) string {
+	var (
+		rel          = r.log.releases[i]
+		tmpls        = r.config.Links
+		link         = rel.link
+		isUnreleased = rel.unreleased()
+		isInitial    = i == len(r.log.releases)-1
+		prev         = r.log.at(i + 1)
+	)
+	switch {
+	case isUnreleased && isInitial:
+		tmpl := tmpls[keyUnreleased]
+		switch {
+		case placeholderPrevious.in(tmpl):
+			// Drop the Unreleased link if it is referencing a non-existent
+			// previous release.
+			link = ""
+		default:
+			// Otherwise, use the template (if any) and discard the
+			// original link.
+			link = tmpl
+		}
+	case isUnreleased:
+		if tmpl := tmpls[keyUnreleased]; tmpl != "" {
+			link = placeholderPrevious.interpolate(tmpl, r.config.tag(prev.version))
+		}
+	case isInitial:
+		if tmpl := tmpls[keyInitialRelease]; tmpl != "" {
+			link = placeholderCurrent.interpolate(tmpl, r.config.tag(rel.version))
+		}
+	default:
+		if tmpl := tmpls[keyRelease]; tmpl != "" {
+			link = placeholderPrevious.interpolate(tmpl, r.config.tag(prev.version))
+			link = placeholderCurrent.interpolate(link, r.config.tag(rel.version))
 		}
 	}
+	return link
 }
 
 func (r *changelogRenderer) renderChanges(w io.Writer, label string, changes []string) {
@@ -983,14 +1312,14 @@ func (m templates) render(w io.Writer, name string, funcs template.FuncMap) erro
 }
 
 var configTemplates = templates{
-	"github": `{{ $repository := prompt "Repository" "user/repository" -}}
+	"github": `{{ $repository := prompt "Repository" (or (gitRemote) "user/repository") -}}
 [links]
   unreleased      = "https://github.com/{{ $repository }}/compare/{PREVIOUS}...HEAD"
   initial-release = "https://github.com/{{ $repository }}/releases/tag/{CURRENT}"
   release         = "https://github.com/{{ $repository }}/compare/{PREVIOUS}...{CURRENT}"
   mention         = "https://github.com/{MENTION}"`,
 
-	"gitlab": `{{ $repository := prompt "Repository" "user/repository" -}}
+	"gitlab": `{{ $repository := prompt "Repository" (or (gitRemote) "user/repository") -}}
 [links]
   unreleased      = "https://gitlab.com/{{ $repository }}/compare/{PREVIOUS}...master"
   initial-release = "https://gitlab.com/{{ $repository }}/-/tags/{CURRENT}"
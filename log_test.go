@@ -284,6 +284,27 @@ func TestChangelog(t *testing.T) {
 			## 0.1.0 - 1970-01-01
 			`,
 		},
+		{
+			name: "yanked release",
+			in: `# Changelog
+			## [0.0.6]
+			## [0.0.5] - 2014-12-13 [YANKED]
+			## 0.0.4 [yanked]
+			[0.0.6]: https://example.com/0.0.6
+			[0.0.5]: https://example.com/0.0.5
+			`,
+			out: `# Changelog
+
+			## [0.0.6]
+
+			## [0.0.5] - 2014-12-13 [YANKED]
+
+			## 0.0.4 [YANKED]
+
+			[0.0.6]: https://example.com/0.0.6
+			[0.0.5]: https://example.com/0.0.5
+			`,
+		},
 		{
 			name: "release note",
 			in: `# Changelog
@@ -496,6 +517,61 @@ func TestChangelog(t *testing.T) {
 			- x
 			`,
 		},
+		{
+			name: "order labels by config",
+			in: `# Changelog
+			## Unreleased
+			### Security
+			- x
+			### Fixed
+			- y
+			### Changed
+			- z
+			`,
+			out: `# Changelog
+
+			## Unreleased
+
+			### Changed
+
+			- z
+
+			### Security
+
+			- x
+
+			### Fixed
+
+			- y
+			`,
+		},
+		{
+			name: "order labels by custom config",
+			in: `# Changelog
+			## Unreleased
+			### Added
+			- x
+			### Removed
+			- y
+			`,
+			out: `# Changelog
+
+			## Unreleased
+
+			### Removed
+
+			- y
+
+			### Added
+
+			- x
+			`,
+			cfg: func() *config {
+				cfg := newConfig()
+				cfg.Changes.Labels = []string{"Removed", "Added"}
+				return cfg
+			}(),
+		},
 		{
 			name: "mix labels",
 			in: `# Changelog
@@ -12,9 +12,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
-	"strconv"
 	"strings"
-	"text/template"
 	"time"
 
 	"golang.org/x/crypto/ssh/terminal"
@@ -46,12 +44,34 @@ type invocation struct {
 		edit      bool
 		release   bool
 		unrelease bool
+		yank      bool
+		unyank    bool
+		fromGit   bool
+		restore   bool
+		check     bool
+		fix       bool
+		projects  bool
+		aggregate bool
+		between   bool
+		stats     bool
+		export    bool
+		importLog bool
 		help      bool
 		version   bool
 	}
 	opts struct {
 		config    string
 		changelog string
+		project   string
+		format    string
+		dryRun    bool
+		strict    bool
+		diff      bool
+		since     string
+		until     string
+		label     string
+		grep      string
+		annotate  bool
 	}
 	args []string
 
@@ -64,6 +84,7 @@ type invocation struct {
 		*changelog
 		*config
 	}
+	lock *fileLock
 }
 
 type editor func(id string, path string) ([]byte, error)
@@ -92,26 +113,114 @@ func (inv *invocation) changelog() *changelog {
 	if log := inv.cache.changelog; log != nil {
 		return log
 	}
-	log, err := loadChangelog(inv.opts.changelog, inv.config())
+	path, err := inv.locateChangelog()
 	if err != nil {
-		panic(err)
+		panic(fatalError{err})
+	}
+	log, err := parseChangelog(path, inv.config())
+	if err != nil {
+		panic(fatalError{err})
 	}
 	inv.cache.changelog = log
 	return log
 }
 
+// changelogForUpdate is like changelog, but locks the changelog before parsing
+// it, so that commands that modify it do not race other kc processes.
+// Read-only commands should use changelog instead, which neither waits for
+// nor creates the lock.
+func (inv *invocation) changelogForUpdate() *changelog {
+	if inv.lock == nil {
+		path, err := inv.locateChangelog()
+		if err != nil {
+			panic(fatalError{err})
+		}
+		inv.lockChangelog(path)
+		// Reparse the changelog, in case it changed before it was locked.
+		inv.cache.changelog = nil
+	}
+	return inv.changelog()
+}
+
+// lockChangelog locks the changelog at path for the rest of the invocation,
+// so that concurrent invocations do not overwrite each other's changes.
+func (inv *invocation) lockChangelog(path string) {
+	if inv.lock != nil {
+		return
+	}
+	timeout, err := inv.config().lockTimeout()
+	if err != nil {
+		panic(fatalError{err})
+	}
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	lock, err := lockFile(path, timeout, func() {
+		inv.errf("Waiting for another kc process to release %s...\n", path)
+	})
+	if err != nil {
+		panic(ioError{err})
+	}
+	inv.lock = lock
+}
+
+func (inv *invocation) unlockChangelog() {
+	if inv.lock == nil {
+		return
+	}
+	if err := inv.lock.unlock(); err != nil {
+		inv.errf("I/O Error: %s\n", err)
+	}
+	inv.lock = nil
+}
+
 func (inv *invocation) config() *config {
 	if cfg := inv.cache.config; cfg != nil {
 		return cfg
 	}
 	cfg, err := loadConfig(inv.opts.config)
 	if err != nil {
-		panic(err)
+		panic(fatalError{err})
+	}
+	if cfg, err = inv.selectProject(cfg); err != nil {
+		panic(fatalError{err})
 	}
 	inv.cache.config = cfg
 	return cfg
 }
 
+// selectProject returns the config of the project specified via --project or,
+// unless a changelog path is given, the one detected from the working
+// directory. If there is no such project, cfg is returned as is.
+func (inv *invocation) selectProject(cfg *config) (*config, error) {
+	name := inv.opts.project
+	if name == "" {
+		var ok bool
+		if inv.opts.changelog != "" {
+			return cfg, nil
+		}
+		if name, ok = cfg.detectProject("."); !ok {
+			return cfg, nil
+		}
+	}
+	return cfg.project(name)
+}
+
+// locateChangelog returns the path of the changelog given via --changelog,
+// that of the selected project, or the one found by searching the working
+// directory and its ancestors.
+func (inv *invocation) locateChangelog() (string, error) {
+	path := inv.opts.changelog
+	if path == "" {
+		path = inv.config().changelog
+	}
+	path, err := locateChangelog(path)
+	if cfg := inv.config(); err != nil && len(cfg.Changelogs) > 0 {
+		err = warnf("%s Select a project via --project: %s", err, strings.Join(cfg.projectNames(), " | "))
+	}
+	return path, err
+}
+
 func (inv *invocation) parse(args []string) error {
 	fs := flag.NewFlagSet("kc", flag.ExitOnError)
 	fs.Usage = func() { inv.doHelp() }
@@ -135,6 +244,28 @@ func (inv *invocation) parse(args []string) error {
 	fs.BoolVar(&inv.cmd.release, "r", false, "")
 	fs.BoolVar(&inv.cmd.unrelease, "unrelease", false, "")
 	fs.BoolVar(&inv.cmd.unrelease, "R", false, "")
+	fs.BoolVar(&inv.cmd.yank, "yank", false, "")
+	fs.BoolVar(&inv.cmd.yank, "y", false, "")
+	fs.BoolVar(&inv.cmd.unyank, "unyank", false, "")
+	fs.BoolVar(&inv.cmd.unyank, "Y", false, "")
+	fs.BoolVar(&inv.cmd.fromGit, "from-git", false, "")
+	fs.BoolVar(&inv.cmd.fromGit, "g", false, "")
+	fs.BoolVar(&inv.cmd.restore, "restore", false, "")
+	fs.BoolVar(&inv.cmd.check, "check", false, "")
+	fs.BoolVar(&inv.cmd.check, "k", false, "")
+	fs.BoolVar(&inv.cmd.fix, "fix", false, "")
+	fs.BoolVar(&inv.cmd.fix, "F", false, "")
+	fs.BoolVar(&inv.cmd.projects, "list-projects", false, "")
+	fs.BoolVar(&inv.cmd.aggregate, "aggregate", false, "")
+	fs.BoolVar(&inv.cmd.aggregate, "A", false, "")
+	fs.BoolVar(&inv.cmd.between, "between", false, "")
+	fs.BoolVar(&inv.cmd.between, "b", false, "")
+	fs.BoolVar(&inv.cmd.stats, "stats", false, "")
+	fs.BoolVar(&inv.cmd.stats, "S", false, "")
+	fs.BoolVar(&inv.cmd.export, "export", false, "")
+	fs.BoolVar(&inv.cmd.export, "x", false, "")
+	fs.BoolVar(&inv.cmd.importLog, "import", false, "")
+	fs.BoolVar(&inv.cmd.importLog, "I", false, "")
 	fs.BoolVar(&inv.cmd.help, "help", false, "")
 	fs.BoolVar(&inv.cmd.help, "h", false, "")
 	fs.BoolVar(&inv.cmd.version, "version", false, "")
@@ -143,10 +274,28 @@ func (inv *invocation) parse(args []string) error {
 	fs.StringVar(&inv.opts.changelog, "c", "", "")
 	fs.StringVar(&inv.opts.config, "config", "", "")
 	fs.StringVar(&inv.opts.config, "C", "", "")
+	fs.StringVar(&inv.opts.project, "project", "", "")
+	fs.StringVar(&inv.opts.project, "P", "", "")
+	fs.StringVar(&inv.opts.format, "format", formatText, "")
+	fs.StringVar(&inv.opts.format, "f", formatText, "")
+	fs.BoolVar(&inv.opts.dryRun, "dry-run", false, "")
+	fs.BoolVar(&inv.opts.dryRun, "n", false, "")
+	fs.BoolVar(&inv.opts.strict, "strict", false, "")
+	fs.BoolVar(&inv.opts.diff, "diff", false, "")
+	fs.StringVar(&inv.opts.since, "since", "", "")
+	fs.StringVar(&inv.opts.until, "until", "", "")
+	fs.StringVar(&inv.opts.label, "label", "", "")
+	fs.StringVar(&inv.opts.grep, "grep", "", "")
+	fs.BoolVar(&inv.opts.annotate, "annotate", false, "")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 	inv.args = fs.Args()
+	format, err := prefix(strings.ToLower(inv.opts.format)).matchAs(formats, "format")
+	if err != nil {
+		return err
+	}
+	inv.opts.format = format
 	return nil
 }
 
@@ -154,6 +303,7 @@ const maxErrorCount = 5
 
 func (inv *invocation) invoke(args []string) (err error) {
 	defer func() {
+		inv.unlockChangelog()
 		switch v := recover().(type) {
 		case nil:
 		case warning:
@@ -162,6 +312,8 @@ func (inv *invocation) invoke(args []string) (err error) {
 			err = v
 		case parseError:
 			err = v
+		case fatalError:
+			err = v.error
 		default:
 			panic(v)
 		}
@@ -218,11 +370,40 @@ func (inv *invocation) invoke(args []string) (err error) {
 		return inv.doRelease()
 	case inv.cmd.unrelease:
 		return inv.doUnrelease()
+	case inv.cmd.yank:
+		return inv.doYank()
+	case inv.cmd.unyank:
+		return inv.doUnyank()
+	case inv.cmd.fromGit:
+		return inv.doFromGit()
+	case inv.cmd.restore:
+		return inv.doRestore()
+	case inv.cmd.check:
+		return inv.doCheck()
+	case inv.cmd.fix:
+		return inv.doFix()
+	case inv.cmd.projects:
+		return inv.doListProjects()
+	case inv.cmd.aggregate:
+		return inv.doAggregate()
+	case inv.cmd.between:
+		return inv.doBetween()
+	case inv.cmd.stats:
+		return inv.doStats()
+	case inv.cmd.export:
+		return inv.doExport()
+	case inv.cmd.importLog:
+		return inv.doImport()
 	default:
 		return inv.doChange()
 	}
 }
 
+// fatalError wraps an error that aborts the invocation from deep within
+// a command, such as failing to load the config or changelog. It is reported
+// like the error it wraps, while any other error panicked with is a bug.
+type fatalError struct{ error }
+
 type warning struct{ error }
 
 func warn(s string) warning {
@@ -251,6 +432,18 @@ Usage:
 Options:
     -c, --changelog <PATH>  Load the changelog found at PATH instead of auto-detecting it.
     -C, --config <PATH>     Load the config found at PATH instead of auto-detecting it.
+    -P, --project <NAME>    Load the changelog of the project NAME, as listed in the changelogs table,
+                            instead of detecting it from the working directory.
+    -f, --format <FORMAT>   Print the output of --show, --list, --list-all, --aggregate, --between,
+                            --stats and --print changelog file as FORMAT, which is one of "text" (default), "json" or "yaml".
+    -n, --dry-run           Print the changes made by any command as a unified diff, but do not write them.
+        --strict            Make --check fail on warnings.
+        --diff              Print the changes made by --fix as a unified diff, but do not write them.
+        --since <DATE>      Only consider releases dated DATE (YYYY-MM-DD) or later.
+        --until <DATE>      Only consider releases dated DATE (YYYY-MM-DD) or earlier.
+        --label <LABEL>     Only consider changes labeled LABEL.
+        --grep <REGEX>      Only consider changes (and release notes) that match REGEX.
+        --annotate          Suffix the changes printed by --between with their release versions.
 
 Commands:
     -i, --init [FILE] [TEMPLATE]  Initialize a config or changelog file.
@@ -262,14 +455,32 @@ Commands:
     -L, --list-all [PATTERN]      Like --list, but include the "Unreleased" section.
     -r, --release [VERSION]       Release the "Unreleased" section.
     -R, --unrelease               Unrelease the last release.
+    -y, --yank [PATTERN]          Mark the last release or releases that match PATTERN as yanked.
+    -Y, --unyank [PATTERN]        Like --yank, but unmark instead.
+    -g, --from-git [RANGE]        Import Conventional Commits in RANGE into the "Unreleased" section.
+        --restore                 Restore the changelog from the backup made by the last change.
+    -k, --check                   Check the changelog for problems without modifying it.
+    -F, --fix                     Rewrite the changelog in canonical form.
+        --list-projects           List the configured projects and their changelog paths.
+    -A, --aggregate               Summarize the releases of all projects, grouped by project and label.
+    -b, --between <FROM> <TO>     Merge the releases after FROM up to TO into a single set of upgrade notes.
+    -S, --stats                   Print release statistics.
+    -x, --export <FMT> [PATTERN]  Export all releases or those that match PATTERN as FMT.
+    -I, --import <SRC> <PATH>     Import the releases of the SRC file at PATH (- for stdin) into the changelog.
     -t, --sort                    Sort releases according to semver.
 
 Arguments:
     FILE      One of "changelog" or "config"
-    TEMPLATE  A template name (discoverable via --print)
+    TEMPLATE  A template name (discoverable via --print), built in or loaded from
+              $XDG_CONFIG_HOME/kc/templates/FILE/*.tmpl or .kc/templates/FILE/*.tmpl
     PROP      A property name (use * for a complete list)
-    PATTERN   An exact version string, a version string prefix or a glob pattern
-    VERSION   A version string that adheres to semver, or one of "patch", "minor", "major"
+    PATTERN   An exact version string, a version string prefix, a glob pattern or a version range,
+              such as ">=1.4.0 <2.0.0", "1.4.0..2.0.0", "^1.2" or "~1.2.3"
+    FMT       An export format, which is one of "html", "atom", "rss", "debian" or "rpm"
+    SRC       An import format, which is one of "towncrier", "github" (releases API JSON) or "debian"
+    RANGE     A git revision range (defaults to the commits since the last release)
+    VERSION   A version string that adheres to semver, or one of "patch", "minor", "major",
+              "prerelease", "alpha", "beta", "rc", "auto"
 
     Note that most arguments may be specified as prefixes.
 
@@ -295,13 +506,7 @@ func (inv *invocation) doInit() (err error) {
 	if file, err = prefix(file).matchAs([]string{"changelog", "config"}, "file type"); err != nil {
 		return err
 	}
-	var tmpls templates
-	switch file {
-	case "changelog":
-		tmpls = changelogTemplates
-	case "config":
-		tmpls = configTemplates
-	}
+	tmpls := inv.templates(file)
 
 	// Choose which template string to initialize.
 	tmpl := "default"
@@ -314,9 +519,7 @@ func (inv *invocation) doInit() (err error) {
 
 	// Write to stdout if we're not connected to a terminal.
 	if !isTerminal(inv.stdout) {
-		return tmpls.render(inv.stdout, tmpl, template.FuncMap{
-			"prompt": inv.promptChoice,
-		})
+		return tmpls.render(inv.stdout, tmpl, inv.templateFuncs())
 	}
 
 	// Otherwise, attempt to write to the provided (or default) file path, but
@@ -338,6 +541,8 @@ func (inv *invocation) doInit() (err error) {
 			dst = inv.opts.changelog
 		case inv.opts.config != "":
 			return errors.New("erroneous path option: --config. Try --changelog instead.")
+		case inv.config().changelog != "":
+			dst = inv.config().changelog
 		default:
 			dst = defaultChangelogName
 		}
@@ -345,20 +550,27 @@ func (inv *invocation) doInit() (err error) {
 	if pathExists(dst) {
 		return fmt.Errorf("%s: file already exists", dst)
 	}
+	if inv.opts.dryRun {
+		buf := new(bytes.Buffer)
+		err := tmpls.render(buf, tmpl, inv.templateFuncs())
+		if err != nil {
+			return err
+		}
+		inv.outf("%s", unifiedDiff(dst, "", buf.String()))
+		return nil
+	}
 	return write(dst, os.O_CREATE|os.O_TRUNC, func(f *os.File) error {
-		return tmpls.render(f, tmpl, template.FuncMap{
-			"prompt": inv.promptChoice,
-		})
+		return tmpls.render(f, tmpl, inv.templateFuncs())
 	})
 }
 
 func (inv *invocation) doSort() error {
-	log := inv.changelog()
+	log := inv.changelogForUpdate()
 	if len(log.releases) < 2 {
 		return warn("No or too few releases to sort.")
 	}
 	log.sort()
-	return log.save(inv.config())
+	return inv.save(log)
 }
 
 func (inv *invocation) doPrint() (err error) {
@@ -367,13 +579,19 @@ func (inv *invocation) doPrint() (err error) {
 			"file": printerFunc(func(inv *invocation, _ string) error {
 				cfg := inv.config()
 				log := inv.changelog()
+				if inv.opts.format != formatText {
+					r := newChangelogRenderer(log.path, cfg, log)
+					return encode(inv.stdout, inv.opts.format, r.changelogDoc())
+				}
 				return log.write(inv.stdout, cfg)
 			}),
 			"path": printerFunc(func(inv *invocation, _ string) error {
 				inv.outln(inv.changelog().path)
 				return nil
 			}),
-			"templates": changelogTemplates,
+			"templates": templatesFunc(func(inv *invocation) templates {
+				return inv.templates("changelog")
+			}),
 			"changes": printerFunc(func(inv *invocation, _ string) error {
 				var n int
 				for _, rel := range inv.changelog().releases {
@@ -387,6 +605,9 @@ func (inv *invocation) doPrint() (err error) {
 				return nil
 			}),
 		},
+		"export": printers{
+			"templates": exportTemplates,
+		},
 		"config": printers{
 			"file": printerFunc(func(inv *invocation, _ string) error {
 				return inv.config().write(inv.stdout)
@@ -395,7 +616,9 @@ func (inv *invocation) doPrint() (err error) {
 				inv.outln(inv.config().path)
 				return nil
 			}),
-			"templates": configTemplates,
+			"templates": templatesFunc(func(inv *invocation) templates {
+				return inv.templates("config")
+			}),
 			"labels": printerFunc(func(inv *invocation, _ string) error {
 				cfg := inv.config()
 				if cfg.Changes.Labels != nil {
@@ -420,6 +643,14 @@ func (f printerFunc) print(inv *invocation, key string) error {
 	return f(inv, key)
 }
 
+// templatesFunc is a printer of templates that are loaded only when printed,
+// since user-defined templates are read from disk.
+type templatesFunc func(*invocation) templates
+
+func (f templatesFunc) print(inv *invocation, key string) error {
+	return f(inv).print(inv, key)
+}
+
 type printers map[string]printer
 
 func (m printers) print(inv *invocation, key string) error {
@@ -470,6 +701,10 @@ func (m printers) flatten(inv *invocation, prefix string) error {
 			for _, name := range keys(next) {
 				inv.outln(join(prefix, join(key, name)))
 			}
+		case templatesFunc:
+			for _, name := range keys(next(inv)) {
+				inv.outln(join(prefix, join(key, name)))
+			}
 		case printerFunc:
 			inv.outln(join(prefix, key))
 		default:
@@ -482,71 +717,113 @@ func (m printers) flatten(inv *invocation, prefix string) error {
 // doList lists the version string for all releases (excluding the Unreleased
 // section).
 func (inv *invocation) doList() error {
-	return inv.list(func(rel *release) string {
-		if rel.unreleased() {
-			return ""
-		}
-		return rel.String()
-	})
+	return inv.list(false)
 }
 
 // doListAll is like doList but also lists the Unreleased section prior to any
 // releases and the number of changes associated with each entry.
 func (inv *invocation) doListAll() error {
-	return inv.list(func(rel *release) string { return rel.details() })
+	return inv.list(true)
 }
 
-func (inv *invocation) list(sprint func(*release) string) error {
+func (inv *invocation) list(all bool) error {
 	pattern := "*"
 	if len(inv.args) > 0 {
 		pattern = inv.args[0]
 	}
-	for _, rel := range inv.changelog().match(pattern) {
-		str := sprint(rel)
-		if str == "" {
-			continue
+	log := inv.changelog()
+	if err := checkPattern(log.versioning(), pattern); err != nil {
+		return err
+	}
+	filter, err := inv.filter(inv.config().Changes.Labels)
+	if err != nil {
+		return err
+	}
+	rs := filter.apply(log.match(pattern))
+	if !all {
+		rs = rs.filter(func(r *release) bool { return !r.unreleased() })
+	}
+	switch format := inv.opts.format; {
+	case format != formatText && all:
+		return encode(inv.stdout, format, releaseSummaryDocs(rs))
+	case format != formatText:
+		vers := []string{}
+		rs.each(func(r *release) { vers = append(vers, r.version) })
+		return encode(inv.stdout, format, vers)
+	}
+	for _, rel := range rs {
+		if all {
+			inv.outln(rel.details())
+		} else {
+			inv.outln(rel.String())
 		}
-		inv.outln(str)
 	}
 	return nil
 }
 
 func (inv *invocation) doShow() (err error) {
 	log := inv.changelog()
+	var pattern string
+	if len(inv.args) > 0 {
+		pattern = inv.args[0]
+	}
+	if err := checkPattern(log.versioning(), pattern); err != nil {
+		return err
+	}
+	if pattern == "" {
+		// Show pending fragments as part of the Unreleased section.
+		frags, err := inv.fragments()
+		if err != nil {
+			return err
+		}
+		log.pushFragments(frags)
+	}
 	if log.empty() {
+		if inv.opts.format != formatText {
+			// Keep the output parsable, e.g., by jq.
+			return encode(inv.stdout, inv.opts.format, []releaseDoc{})
+		}
 		return warn("Nothing to show.")
 	}
 
 	out := &changelog{path: log.path}
 	cfg := inv.config()
+	filter, err := inv.filter(cfg.Changes.Labels)
+	if err != nil {
+		return err
+	}
 	defer func() {
-		if out.empty() {
+		switch {
+		case inv.opts.format != formatText:
+			// Encode no matches as an empty list rather than a warning, so
+			// that the output remains parsable.
+			r := newChangelogRenderer(log.path, cfg, log)
+			err = encode(inv.stdout, inv.opts.format, r.releaseDocs(out.releases))
+		case out.empty():
 			err = warnNoMatches
-		} else {
+		default:
 			cfg.writeReleaseLinks = false
 			err = out.write(inv.stdout, cfg)
 		}
 	}()
-	var pattern string
-	if len(inv.args) > 0 {
-		pattern = inv.args[0]
-	}
-	if pattern == "" {
+	switch {
+	case pattern == "" && filter.empty():
 		out.append(log.head())
-		return
-	}
-	for _, r := range log.match(pattern) {
+	case pattern == "":
+		// Filter all releases, including the Unreleased section.
+		out.releases = filter.apply(log.releases)
+	default:
 		// Exclude the Unreleased section.
-		if r.unreleased() {
-			continue
-		}
-		out.append(r)
+		rs := log.match(pattern).filter(func(r *release) bool {
+			return !r.unreleased()
+		})
+		out.releases = filter.apply(rs)
 	}
 	return
 }
 
 func (inv *invocation) doEdit() (err error) {
-	log := inv.changelog()
+	log := inv.changelogForUpdate()
 	if log.empty() {
 		return warn("Nothing to edit.")
 	}
@@ -682,7 +959,7 @@ func (inv *invocation) doEdit() (err error) {
 
 	defer func() {
 		if err == nil {
-			err = log.save(inv.config())
+			err = inv.save(log)
 		}
 	}()
 	var pattern string
@@ -720,7 +997,7 @@ func (inv *invocation) doEdit() (err error) {
 }
 
 func (inv *invocation) doDelete() (err error) {
-	log := inv.changelog()
+	log := inv.changelogForUpdate()
 	if log.empty() {
 		return warn("Nothing to delete.")
 	}
@@ -734,7 +1011,7 @@ func (inv *invocation) doDelete() (err error) {
 		if err == nil {
 			switch {
 			case ok:
-				err = log.save(inv.config())
+				err = inv.save(log)
 			case !ok:
 				err = warnNoChanges
 			}
@@ -767,7 +1044,12 @@ func (inv *invocation) doDelete() (err error) {
 }
 
 func (inv *invocation) doRelease() error {
-	log := inv.changelog()
+	log := inv.changelogForUpdate()
+	frags, err := inv.fragments()
+	if err != nil {
+		return err
+	}
+	log.pushFragments(frags)
 	unrel := log.unreleased()
 	if unrel == nil || (unrel.changeCount() == 0 && unrel.note == "") {
 		return warn("No unreleased changes.")
@@ -778,9 +1060,18 @@ func (inv *invocation) doRelease() error {
 		arg                    = "patch"
 		do  func(string) error = inv.doReleaseBump
 	)
+	if cal, ok := unprefixed(log.versioning()).(*calverFormat); ok {
+		arg = ""
+		do = func(typ string) error {
+			if typ != "" {
+				return fmt.Errorf("calver versions are derived from the date, not bumped: %s", typ)
+			}
+			return inv.doReleaseCalver(cal)
+		}
+	}
 	if len(inv.args) > 0 {
 		arg = inv.args[0]
-		if reVersion.MatchString(arg) {
+		if log.versioning().valid(arg) {
 			if log.has(arg) {
 				do = inv.doReleaseMerge
 			} else {
@@ -796,43 +1087,105 @@ func (inv *invocation) doRelease() error {
 	if err := log.validate(cfg); err != nil {
 		return err
 	}
-	return log.save(cfg)
+	if err := inv.save(log); err != nil {
+		return err
+	}
+	if inv.opts.dryRun {
+		return nil
+	}
+	return removeFragments(frags)
+}
+
+// save writes log to disk or, in dry-run mode, prints the changes that would
+// be written as a unified diff.
+func (inv *invocation) save(log *changelog) error {
+	cfg := inv.config()
+	if !inv.opts.dryRun {
+		return log.save(cfg)
+	}
+	buf := new(bytes.Buffer)
+	if err := log.write(buf, cfg); err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(log.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	inv.outf("%s", unifiedDiff(log.path, string(data), buf.String()))
+	return nil
+}
+
+// fragments returns the pending change fragments, if enabled.
+func (inv *invocation) fragments() ([]fragment, error) {
+	cfg := inv.config()
+	dir, ok := cfg.fragmentsDir(inv.changelog().path)
+	if !ok {
+		return nil, nil
+	}
+	return loadFragments(dir, cfg)
 }
 
 func (inv *invocation) doReleaseBump(typ string) error {
-	typ, err := prefix(typ).matchAs([]string{"major", "minor", "patch"}, "version number")
+	typ, err := matchBumpType(typ)
 	if err != nil {
 		return err
 	}
 
 	var (
-		log = inv.changelog()
-		ver = []int{0, 0, 0}
+		log    = inv.changelog()
+		ver    version
+		prefix = inv.config().Versioning.Prefix
 	)
+	if typ == bumpAuto {
+		if typ, err = inv.config().bumpType(log.unreleased()); err != nil {
+			return err
+		}
+	}
 	if prev := log.at(1); prev != nil {
-		// Use the previous version string as a starting point.
-		for i, s := range reVersion.FindStringSubmatch(prev.version)[1:] {
-			ver[i], _ = strconv.Atoi(s)
-		}
-	}
-	switch typ {
-	case "major":
-		ver[0]++
-		ver[1] = 0
-		ver[2] = 0
-	case "minor":
-		ver[1]++
-		ver[2] = 0
-	case "patch":
-		ver[2]++
-	}
-	log.release(fmt.Sprintf("%d.%d.%d", ver[0], ver[1], ver[2]), time.Now())
+		// Use the previous version string as a starting point, and retain
+		// its prefix, if any.
+		p, s := splitVersion(log.versioning(), prev.version)
+		v, ok := parseVersion(s)
+		if !ok {
+			return fmt.Errorf("cannot bump %s: not a semver version string", prev.version)
+		}
+		ver, prefix = v, p
+	}
+	next, err := ver.bump(typ)
+	if err != nil {
+		return err
+	}
+	log.release(prefix+next.String(), time.Now())
+	inv.outln(log.head().version)
+	return nil
+}
+
+func (inv *invocation) doReleaseCalver(f *calverFormat) error {
+	var (
+		log    = inv.changelog()
+		prev   string
+		prefix = inv.config().Versioning.Prefix
+	)
+	if rel := log.at(1); rel != nil {
+		prefix, prev = splitVersion(log.versioning(), rel.version)
+	}
+	next, err := f.next(prev, time.Now())
+	if err != nil {
+		return err
+	}
+	log.release(prefix+next, time.Now())
 	inv.outln(log.head().version)
 	return nil
 }
 
 func (inv *invocation) doReleaseVersion(ver string) error {
 	log := inv.changelog()
+	scheme := log.versioning()
+	for _, rel := range log.releases {
+		if scheme.valid(rel.version) && scheme.compare(ver, rel.version) == 0 {
+			return fmt.Errorf("%s has the same precedence as %s", ver, rel.version)
+		}
+	}
 	log.release(ver, time.Now())
 	inv.outln(log.head().version)
 	return nil
@@ -849,11 +1202,11 @@ func (inv *invocation) doReleaseMerge(ver string) error {
 		rel   = log.get(ver)
 		unrel = log.pop()
 	)
-	for label, chs := range unrel.changes {
-		for _, ch := range chs {
+	for _, g := range unrel.changes {
+		for _, ch := range g.changes {
 			// NOTE: the changelog is validated by the calling function so it
 			// does not matter if the label is invalid at this point.
-			label, _ = cfg.label(label)
+			label, _ := cfg.label(g.label)
 			rel.pushChange(label, ch)
 		}
 	}
@@ -869,7 +1222,7 @@ func (inv *invocation) doReleaseMerge(ver string) error {
 }
 
 func (inv *invocation) doUnrelease() error {
-	log := inv.changelog()
+	log := inv.changelogForUpdate()
 	if log.empty() || len(log.releases) == 1 && log.head().unreleased() {
 		return warn("Nothing to unrelease.")
 	}
@@ -884,17 +1237,421 @@ func (inv *invocation) doUnrelease() error {
 		head.version = "Unreleased"
 		head.date = time.Time{}
 		head.link = ""
+		head.yanked = false
 	}
 	cfg := inv.config()
 	if err := log.validate(cfg); err != nil {
 		return err
 	}
+	return inv.save(log)
+}
+
+func (inv *invocation) doYank() error {
+	return inv.yank("yank", true)
+}
+
+func (inv *invocation) doUnyank() error {
+	return inv.yank("unyank", false)
+}
+
+func (inv *invocation) yank(act string, yanked bool) error {
+	log := inv.changelogForUpdate()
+	var vers []string
+	switch {
+	case len(inv.args) > 0:
+		vers = inv.promptReleases(act, inv.args[0])
+	case log.latest() != nil:
+		vers = []string{log.latest().version}
+	}
+	if len(vers) == 0 {
+		return warnNoMatches
+	}
+	var changes int
+	for _, ver := range vers {
+		rel := log.get(ver)
+		if rel.yanked == yanked {
+			continue
+		}
+		rel.yanked = yanked
+		inv.outln(rel.version)
+		changes++
+	}
+	if changes == 0 {
+		return warnNoChanges
+	}
+	return inv.save(log)
+}
+
+func (inv *invocation) doFromGit() error {
+	var (
+		log = inv.changelogForUpdate()
+		cfg = inv.config()
+		rng = "HEAD"
+	)
+	switch {
+	case len(inv.args) > 0:
+		rng = inv.args[0]
+	case log.latest() != nil:
+		rng = cfg.tag(log.latest().version) + "..HEAD"
+	}
+	commits, err := gitLog(filepath.Dir(log.path), rng)
+	if err != nil {
+		return err
+	}
+
+	// Collect existing changes to avoid importing duplicates.
+	seen := make(map[string]bool)
+	if unrel := log.unreleased(); unrel != nil {
+		for _, g := range unrel.changes {
+			for _, ch := range g.changes {
+				seen[ch] = true
+			}
+		}
+	}
+	var changes int
+	for _, c := range commits {
+		cc, ok := parseConventionalCommit(c.message)
+		if !ok {
+			continue
+		}
+		label, ok, err := cfg.commitLabel(cc)
+		if err != nil {
+			return err
+		}
+		if !ok || seen[cc.description] {
+			continue
+		}
+		seen[cc.description] = true
+		log.pushChange(label, cc.description)
+		changes++
+	}
+	if changes == 0 {
+		return warnNoChanges
+	}
+	if err := log.validate(cfg); err != nil {
+		return err
+	}
+	return inv.save(log)
+}
+
+func (inv *invocation) doRestore() error {
+	// NOTE: the changelog is not parsed, since the point of restoring it may
+	// be that it no longer parses.
+	path, err := inv.locateChangelog()
+	if err != nil {
+		return err
+	}
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	inv.lockChangelog(path)
+	bak := path + backupSuffix
+	if !pathExists(bak) {
+		return warnf("No backup found at %s.", bak)
+	}
+	if inv.opts.dryRun {
+		cur, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		old, err := ioutil.ReadFile(bak)
+		if err != nil {
+			return err
+		}
+		inv.outf("%s", unifiedDiff(path, string(cur), string(old)))
+		return nil
+	}
+	if !inv.confirmf('N', "Restore %s from %s?", path, bak) {
+		return warnNoChanges
+	}
+	// Swap the changelog and its backup, so that restoring again undoes the
+	// restore.
+	data, err := ioutil.ReadFile(bak)
+	if err != nil {
+		return ioError{err}
+	}
+	return writeAtomic(path, true, func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+}
+
+func (inv *invocation) doCheck() error {
+	path, err := inv.locateChangelog()
+	if err != nil {
+		return err
+	}
+	issues, err := checkChangelog(path, inv.config(), time.Now())
+	if err != nil {
+		return err
+	}
+	var n int
+	for _, issue := range issues {
+		inv.outln(issue.format(path))
+		if inv.opts.strict || !issue.warning() {
+			n++
+		}
+	}
+	if n > 0 {
+		return warnf("Found %d %s.", n, pluralize("problem", n))
+	}
+	return nil
+}
+
+func (inv *invocation) doFix() error {
+	path, err := inv.locateChangelog()
+	if err != nil {
+		return err
+	}
+	inv.lockChangelog(path)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	cfg := inv.config()
+	log, err := newTolerantChangelogParser(path, cfg).parse(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	log.path = path
+	log.sort()
+	buf := new(bytes.Buffer)
+	if err := log.write(buf, cfg); err != nil {
+		return err
+	}
+	if buf.String() == string(data) {
+		return warnNoChanges
+	}
+	if inv.opts.diff || inv.opts.dryRun {
+		inv.outf("%s", unifiedDiff(path, string(data), buf.String()))
+		return nil
+	}
 	return log.save(cfg)
 }
 
-func (inv *invocation) doChange() (err error) {
+// doListProjects lists the projects of the changelogs table, along with the
+// paths of their changelogs.
+func (inv *invocation) doListProjects() error {
+	cfg, err := loadConfig(inv.opts.config)
+	if err != nil {
+		return err
+	}
+	names := cfg.projectNames()
+	if len(names) == 0 {
+		return warn("No projects configured.")
+	}
+	var width int
+	for _, name := range names {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	for _, name := range names {
+		inv.outf("%-*s  %s\n", width, name, cfg.projectPath(cfg.Changelogs[name]))
+	}
+	return nil
+}
+
+// doBetween merges the releases that succeed one version up to another into
+// a single release, e.g., for users that upgrade across several releases.
+func (inv *invocation) doBetween() error {
+	if len(inv.args) != 2 {
+		return errors.New("--between expects two versions: FROM and TO")
+	}
+	from, to := inv.args[0], inv.args[1]
+	log := inv.changelog()
+	cfg := inv.config()
+	filter, err := inv.filter(cfg.Changes.Labels)
+	if err != nil {
+		return err
+	}
+	rs, err := log.between(from, to)
+	if err != nil {
+		return err
+	}
+	rs = filter.apply(rs)
+	if len(rs) == 0 {
+		return warnNoMatches
+	}
+	rel := mergeReleases(fmt.Sprintf("Upgrading from %s to %s", from, to), rs, inv.opts.annotate)
+	if format := inv.opts.format; format != formatText {
+		r := newChangelogRenderer(log.path, cfg, log)
+		return encode(inv.stdout, format, r.releaseDocs(releases{rel}))
+	}
+	out := &changelog{path: log.path}
+	out.append(rel)
+	cfg.writeReleaseLinks = false
+	return out.write(inv.stdout, cfg)
+}
+
+// doExport renders the released part of the changelog in an export format,
+// optionally limited to the releases that match a pattern.
+func (inv *invocation) doExport() error {
+	if len(inv.args) == 0 {
+		return fmt.Errorf("--export expects a format: %s", strings.Join(exportFormats, " | "))
+	}
+	format, err := prefix(strings.ToLower(inv.args[0])).matchAs(exportFormats, "export format")
+	if err != nil {
+		return err
+	}
+	pattern := "*"
+	if len(inv.args) > 1 {
+		pattern = inv.args[1]
+	}
 	log := inv.changelog()
 	cfg := inv.config()
+	if err := checkPattern(log.versioning(), pattern); err != nil {
+		return err
+	}
+	filter, err := inv.filter(cfg.Changes.Labels)
+	if err != nil {
+		return err
+	}
+	rs := filter.apply(log.match(pattern)).filter(func(r *release) bool {
+		return !r.unreleased()
+	})
+	if len(rs) == 0 {
+		return warnNoMatches
+	}
+	tmpl, err := cfg.exportTemplate(format)
+	if err != nil {
+		return err
+	}
+	r := newChangelogRenderer(log.path, cfg, log)
+	doc := exportDoc{
+		changelogDoc: r.changelogDoc(),
+		scheme:       log.versioning(),
+	}
+	doc.Releases = r.releaseDocs(rs)
+	switch format {
+	case exportAtom, exportRSS:
+		doc.Feed, err = cfg.feed(format, doc.changelogDoc)
+	case exportDebian, exportRPM:
+		doc.Packaging, err = cfg.packaging(format, doc.changelogDoc)
+	}
+	if err != nil {
+		return err
+	}
+	return export(inv.stdout, format, tmpl, doc)
+}
+
+// doImport merges the releases of a file in another format into the
+// changelog. Releases that are already part of the changelog are kept as is,
+// and reported if the imported ones differ.
+func (inv *invocation) doImport() error {
+	if len(inv.args) != 2 {
+		return fmt.Errorf("--import expects a format and a file: %s", strings.Join(importFormats, " | "))
+	}
+	format, err := prefix(strings.ToLower(inv.args[0])).matchAs(importFormats, "import format")
+	if err != nil {
+		return err
+	}
+	path := inv.args[1]
+	var r io.Reader = inv.stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return ioError{err}
+		}
+		defer f.Close()
+		r = f
+	}
+	log := inv.changelogForUpdate()
+	cfg := inv.config()
+	rs, err := importers[format](path, r, log.versioning())
+	if err != nil {
+		return err
+	}
+	for _, rel := range rs {
+		if err := cfg.importLabels(rel); err != nil {
+			return err
+		}
+	}
+	added, conflicts := log.mergeImported(rs)
+	for _, rel := range conflicts {
+		inv.errf("Skipped %s, which differs from the existing release.\n", rel)
+	}
+	if added == 0 {
+		return warnNoChanges
+	}
+	if err := log.validate(cfg); err != nil {
+		return err
+	}
+	return inv.save(log)
+}
+
+// doStats prints release statistics. The time changes sat in the Unreleased
+// section is derived from git blame, if the changelog is tracked by git.
+func (inv *invocation) doStats() error {
+	path, err := inv.locateChangelog()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cfg := inv.config()
+	p := newChangelogParser(path, cfg)
+	log, err := p.parse(f)
+	if err != nil {
+		return err
+	}
+	if log.empty() {
+		return warn("Nothing to show.")
+	}
+	added, _ := gitBlameTimes(path)
+	st := computeStats(log, cfg, p.items, added)
+	if format := inv.opts.format; format != formatText {
+		return encode(inv.stdout, format, st.doc())
+	}
+	return st.write(inv.stdout)
+}
+
+// doAggregate summarizes the releases of all projects, optionally limited to
+// a date range.
+func (inv *invocation) doAggregate() error {
+	// Labels are matched exactly, since they may differ across projects.
+	filter, err := inv.filter(nil)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfig(inv.opts.config)
+	if err != nil {
+		return err
+	}
+	comps, err := aggregate(cfg, filter)
+	if err != nil {
+		return err
+	}
+	if len(comps) == 0 {
+		return warnNoMatches
+	}
+	if format := inv.opts.format; format != formatText {
+		docs := make([]componentDoc, len(comps))
+		for i, c := range comps {
+			docs[i] = c.doc()
+		}
+		return encode(inv.stdout, format, docs)
+	}
+	for i, c := range comps {
+		if i > 0 {
+			inv.outln("")
+		}
+		log := &changelog{path: c.log.path}
+		log.append(c.summary())
+		c.config.writeReleaseLinks = false
+		if err := log.write(inv.stdout, c.config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (inv *invocation) doChange() (err error) {
+	log := inv.changelogForUpdate()
+	cfg := inv.config()
 	var (
 		label  string
 		change string
@@ -907,7 +1664,7 @@ func (inv *invocation) doChange() (err error) {
 		label = inv.args[0]
 		change = strings.Join(inv.args[1:], " ")
 	}
-	change = strings.TrimSpace(change)
+	change = normalizeChange(change)
 
 	edit := func(change *string) error {
 		path, err := newTempPath("change", ".md")
@@ -918,13 +1675,14 @@ func (inv *invocation) doChange() (err error) {
 		if err != nil {
 			return err
 		}
-		text := strings.TrimSpace(string(data))
+		text := normalizeChange(string(data))
 		if text == "" {
 			return warnNoChanges
 		}
 		*change = text
 		return nil
 	}
+	var frag fragment
 	push := func(label, change string) error {
 		if change == "" {
 			if err := edit(&change); err != nil {
@@ -932,6 +1690,7 @@ func (inv *invocation) doChange() (err error) {
 			}
 		}
 		log.pushChange(label, change)
+		frag = fragment{label: label, text: change}
 		return nil
 	}
 
@@ -939,9 +1698,21 @@ func (inv *invocation) doChange() (err error) {
 		if err == nil {
 			err = log.validate(cfg)
 		}
-		if err == nil {
-			err = log.save(cfg)
+		if err != nil {
+			return
+		}
+		// In fragments mode, the change is only validated against the
+		// changelog, which is left untouched.
+		if dir, ok := cfg.fragmentsDir(log.path); ok {
+			if inv.opts.dryRun {
+				name := filepath.Join(fragmentDir(dir, frag.label), "*"+fragmentExt)
+				inv.outf("%s", unifiedDiff(name, "", frag.text+"\n"))
+				return
+			}
+			_, err = writeFragment(dir, frag.label, frag.text)
+			return
 		}
+		err = inv.save(log)
 	}()
 	switch {
 	case label == "" && len(allow) == 0:
@@ -957,15 +1728,20 @@ func (inv *invocation) doChange() (err error) {
 
 func (inv *invocation) promptReleases(act, pat string) []string {
 	log := inv.changelog()
+	if err := checkPattern(log.versioning(), pat); err != nil {
+		panic(fatalError{err})
+	}
 	return inv.promptList("Releases", act, pat, log.stringer(func(r *release) string {
 		if r.unreleased() {
 			return ""
 		}
 		return r.version
-	}))
+	}), func(vals []string, pat string) []string {
+		return matchVersions(log.versioning(), vals, pat)
+	})
 }
 
-func (inv *invocation) promptList(title, act, pat string, list func() []string) []string {
+func (inv *invocation) promptList(title, act, pat string, list func() []string, match func([]string, string) []string) []string {
 	if pat == "" {
 		pat = "*"
 	}
@@ -973,7 +1749,7 @@ func (inv *invocation) promptList(title, act, pat string, list func() []string)
 	defer scr.clear()
 RETRY:
 	all := list()
-	items := matchPattern(all, pat)
+	items := match(all, pat)
 	switch len(items) {
 	case 0:
 		return nil
@@ -1154,7 +1930,10 @@ func loadConfig(userpath string) (*config, error) {
 			return err
 		}
 		cfg.path = other.path
-		return cfg.merge(other)
+		if err := cfg.merge(other); err != nil {
+			return err
+		}
+		return cfg.initVersioning()
 	}
 
 	// Attempt to merge a user-specified file with the default config.
@@ -1190,18 +1969,18 @@ func findConfig(dir string) (string, error) {
 	return findConfig(up)
 }
 
-func loadChangelog(userpath string, cfg *config) (*changelog, error) {
+func locateChangelog(userpath string) (string, error) {
 	if userpath != "" {
-		return parseChangelog(userpath, cfg)
+		return userpath, nil
 	}
 	path, err := findChangelog(".")
 	if err != nil {
 		if err == errFileNotFound {
 			err = warn("No changelog found.")
 		}
-		return nil, err
+		return "", err
 	}
-	return parseChangelog(path, cfg)
+	return path, nil
 }
 
 const defaultChangelogName = "CHANGELOG.md"
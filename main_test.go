@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
@@ -18,6 +19,7 @@ func TestInvoke(t *testing.T) {
 	for _, test := range []struct {
 		name   string
 		args   []string
+		dir    string // working directory
 		stdin  string
 		edits  files  // release:text
 		create files  // path:text
@@ -72,7 +74,7 @@ func TestInvoke(t *testing.T) {
 		{
 			name:   "print top-level",
 			args:   []string{"-p"},
-			stdout: "changelog\nconfig\n",
+			stdout: "changelog\nconfig\nexport\n",
 		},
 		{
 			name: "print all",
@@ -89,6 +91,11 @@ func TestInvoke(t *testing.T) {
 			config.path
 			config.templates.github
 			config.templates.gitlab
+			export.templates.atom
+			export.templates.debian
+			export.templates.html
+			export.templates.rpm
+			export.templates.rss
 			`,
 		},
 		{
@@ -210,6 +217,33 @@ func TestInvoke(t *testing.T) {
 			`,
 			},
 		},
+		{
+			name: "sort pre-releases",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0-rc.1
+				## 1.0.0
+				## 1.0.0-beta.11
+				## 1.0.0-beta.2
+				## 0.9.0
+				`,
+			},
+			args: []string{"--sort"},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+			## 1.0.0
+
+			## 1.0.0-rc.1
+
+			## 1.0.0-beta.11
+
+			## 1.0.0-beta.2
+
+			## 0.9.0
+			`,
+			},
+		},
 		{
 			name: "list",
 			args: []string{"-l"},
@@ -306,6 +340,54 @@ func TestInvoke(t *testing.T) {
 			2.0.0 (1 change)
 			`,
 		},
+		{
+			name: "list all yanked",
+			args: []string{"-L"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 1.0.1
+				- Change
+				## 1.0.0 [YANKED]
+				- Change
+				`,
+			},
+			stdout: `1.0.1 (1 change)
+			1.0.0 (1 change) [YANKED]
+			`,
+		},
+		{
+			name: "list json",
+			args: []string{"-f", "json", "-l", "2"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				## 1.0.0
+				`,
+			},
+			stdout: "[]\n",
+		},
+		{
+			name: "list all yaml",
+			args: []string{"-f", "yaml", "-L"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 1.0.0 - 2020-01-02
+				`,
+			},
+			stdout: `- version: "Unreleased"
+			  unreleased: true
+			  date: ""
+			  yanked: false
+			  changes: 1
+			- version: "1.0.0"
+			  unreleased: false
+			  date: "2020-01-02"
+			  yanked: false
+			  changes: 0
+			`,
+		},
 		{
 			name: "list all pattern",
 			args: []string{"-L", "unrel"},
@@ -437,6 +519,104 @@ func TestInvoke(t *testing.T) {
 			- c
 			`,
 		},
+		{
+			name: "show exact version",
+			args: []string{"-s", "1.0.0"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 1.0.10
+				- d
+				## 1.0.0
+				- c
+				## 1.0.0-rc.2
+				- b
+				## 1.0.0-rc.1
+				- a
+				`,
+			},
+			stdout: `## 1.0.0
+
+			- c
+			`,
+		},
+		{
+			name: "show json",
+			args: []string{"-f", "json", "-s", "1.0.0"},
+			create: files{
+				".kcrc": `
+				[links]
+					release = "rel/{PREVIOUS}...{CURRENT}"
+					mention = "users/{MENTION}"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0 - 2020-01-02
+				Thanks, @user!
+				### Fixed
+				- b
+				### Added
+				- a
+				## 0.1.0
+				`,
+			},
+			stdout: `[
+			  {
+			    "version": "1.0.0",
+			    "unreleased": false,
+			    "date": "2020-01-02",
+			    "yanked": false,
+			    "link": "rel/0.1.0...1.0.0",
+			    "note": "Thanks, [@user](users/user)!",
+			    "changes": [
+			      {
+			        "label": "Added",
+			        "items": [
+			          "a"
+			        ]
+			      },
+			      {
+			        "label": "Fixed",
+			        "items": [
+			          "b"
+			        ]
+			      }
+			    ]
+			  }
+			]
+			`,
+		},
+		{
+			name: "print changelog yaml",
+			args: []string{"-f", "y", "-p", "ch", "f"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- "a"
+				  b
+				## 0.1.0 [YANKED]
+				`,
+			},
+			stdout: `title: "Changelog"
+			header: ""
+			releases:
+			  - version: "Unreleased"
+			    unreleased: true
+			    date: ""
+			    yanked: false
+			    link: ""
+			    note: ""
+			    changes:
+			      - label: ""
+			        items:
+			          - "\"a\"\nb"
+			  - version: "0.1.0"
+			    unreleased: false
+			    date: ""
+			    yanked: true
+			    link: ""
+			    note: ""
+			    changes: []
+			`,
+		},
 		{
 			name: "show no matches",
 			args: []string{"-s", "1"},
@@ -450,6 +630,26 @@ func TestInvoke(t *testing.T) {
 			},
 			stderr: "No matches.\n",
 		},
+		{
+			name: "show json no matches",
+			args: []string{"-f", "json", "-s", "1"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				`,
+			},
+			stdout: "[]\n",
+		},
+		{
+			name: "show json empty",
+			args: []string{"-f", "json", "-s"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				`,
+			},
+			stdout: "[]\n",
+		},
 		{
 			name:  "delete empty",
 			args:  []string{"-d", "*"},
@@ -976,6 +1176,256 @@ func TestInvoke(t *testing.T) {
 				`,
 			},
 		},
+		{
+			name:   "release rc",
+			args:   []string{"-r", "rc"},
+			stdout: "1.2.4-rc.1\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 1.2.3
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 1.2.4-rc.1 - {TEST_DATE}
+
+				- a
+
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "release prerelease",
+			args:   []string{"-r", "pre"},
+			stdout: "1.2.4-beta.2\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 1.2.4-beta.1
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 1.2.4-beta.2 - {TEST_DATE}
+
+				- a
+
+				## 1.2.4-beta.1
+				`,
+			},
+		},
+		{
+			name:   "release patch short",
+			args:   []string{"-r", "p"},
+			stdout: "0.1.1\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 0.1.0
+				`,
+			},
+		},
+		{
+			name:   "release prerelease short",
+			args:   []string{"-r", "pr"},
+			stdout: "0.1.1-rc.1\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 0.1.0
+				`,
+			},
+		},
+		{
+			name:   "release patch after pre-release",
+			args:   []string{"-r"},
+			stdout: "1.2.4\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 1.2.4-rc.2
+				## 1.2.3
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 1.2.4 - {TEST_DATE}
+
+				- a
+
+				## 1.2.4-rc.2
+
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "release version with build metadata",
+			args:   []string{"-r", "1.2.3+build"},
+			stderr: "Error: 1.2.3+build has the same precedence as 1.2.3\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "release auto patch",
+			args:   []string{"-r", "auto"},
+			stdout: "1.2.4\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Fixed
+				- a
+				## 1.2.3
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 1.2.4 - {TEST_DATE}
+
+				### Fixed
+
+				- a
+
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "release auto minor",
+			args:   []string{"-r", "au"},
+			stdout: "1.3.0\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Added
+				- a
+				### Fixed
+				- b
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "release auto major",
+			args:   []string{"-r", "auto"},
+			stdout: "2.0.0\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Added
+				- a
+				### Removed
+				- b
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "release auto custom config",
+			args:   []string{"-r", "auto"},
+			stdout: "2.0.0\n",
+			create: files{
+				".kcrc": `
+				[release]
+					breaking = "(breaking)"
+				[release.bumps]
+					fixed = "minor"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Fixed
+				- a (breaking)
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "release auto breaking marker",
+			args:   []string{"-r", "auto"},
+			stdout: "2.0.0\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Fixed
+				- BREAKING: a
+				- read BREAKING_CHANGES_ENV
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "release auto breaking label",
+			args:   []string{"-r", "auto"},
+			stdout: "2.0.0\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Changed
+				- a
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "release auto unbroken",
+			args:   []string{"-r", "auto"},
+			stdout: "1.2.4\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Fixed
+				- read BREAKING_CHANGES_ENV
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name: "release dry run",
+			args: []string{"--dry-run", "-r", "min"},
+			stdout: `0.2.0
+			--- a/CHANGELOG.md
+			+++ b/CHANGELOG.md
+			@@ -1,4 +1,7 @@
+			 # Changelog
+			-## Unreleased
+			+
+			+## 0.2.0 - {TEST_DATE}
+			+
+			 - a
+			+
+			 ## 0.1.0
+			`,
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 0.1.0
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 0.1.0
+				`,
+			},
+		},
 		{
 			name:   "release version",
 			args:   []string{"-r", "5.0.0"},
@@ -1174,8 +1624,1815 @@ func TestInvoke(t *testing.T) {
 			},
 		},
 		{
-			name: "change label prefix",
-			args: []string{"a"},
+			name:   "yank last release",
+			args:   []string{"-y"},
+			stdout: "0.2.0\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				## 0.2.0 - 2020-01-02
+				## 0.1.0 - 2020-01-01
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## Unreleased
+
+				## 0.2.0 - 2020-01-02 [YANKED]
+
+				## 0.1.0 - 2020-01-01
+				`,
+			},
+		},
+		{
+			name:   "yank already yanked",
+			args:   []string{"-y", "0.1.0"},
+			stderr: "No changes.\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 0.1.0 [YANKED]
+				`,
+			},
+		},
+		{
+			name:   "unyank release",
+			args:   []string{"-Y", "0.1"},
+			stdout: "0.1.0\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 0.2.0
+				## 0.1.0 - 2020-01-01 [YANKED]
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 0.2.0
+
+				## 0.1.0 - 2020-01-01
+				`,
+			},
+		},
+		{
+			name: "change with backup",
+			args: []string{"a", "new change"},
+			create: files{
+				".kcrc": `
+				[files]
+					backup = true
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 0.1.0
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## Unreleased
+
+				### Added
+
+				- new change
+
+				## 0.1.0
+				`,
+				"CHANGELOG.md.kc.bak": `# Changelog
+				## 0.1.0
+				`,
+			},
+		},
+		{
+			name:   "restore",
+			args:   []string{"--restore"},
+			stdin:  "y",
+			stderr: "Restore CHANGELOG.md from CHANGELOG.md.kc.bak? [yN] ",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Broken
+				`,
+				"CHANGELOG.md.kc.bak": `# Changelog
+				## 0.1.0
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+				## 0.1.0
+				`,
+				"CHANGELOG.md.kc.bak": `# Changelog
+				## Broken
+				`,
+			},
+		},
+		{
+			name:   "restore without backup",
+			args:   []string{"--restore"},
+			stderr: "No backup found at CHANGELOG.md.kc.bak.\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				`,
+			},
+		},
+		{
+			name: "show fragments normalized",
+			args: []string{"-s"},
+			create: files{
+				".kcrc": `
+				[fragments]
+					dir = "changelog.d"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				`,
+				"changelog.d/fixed/1.md": "- a fix\n\n\n",
+				"changelog.d/fixed/2.md": "- \n",
+				"changelog.d/fixed/3.md": "\n\n",
+			},
+			stdout: `## Unreleased
+
+			### Fixed
+
+			- a fix
+			`,
+		},
+		{
+			name: "show fragments",
+			args: []string{"-s"},
+			create: files{
+				".kcrc": `
+				[fragments]
+					dir = "changelog.d"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Fixed
+				- old fix
+				## 0.1.0
+				`,
+				"changelog.d/fixed/2.md":   "new fix\n",
+				"changelog.d/added/1.md":   "new feature\n",
+				"changelog.d/added/README": "ignored",
+			},
+			stdout: `## Unreleased
+
+			### Added
+
+			- new feature
+
+			### Fixed
+
+			- old fix
+			- new fix
+			`,
+		},
+		{
+			name: "show fragments unknown label",
+			args: []string{"-s"},
+			create: files{
+				".kcrc": `
+				[fragments]
+					dir = "changelog.d"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 0.1.0
+				`,
+				"changelog.d/misc/1.md": "change\n",
+			},
+			stderr: "Error: fragment directory does not match any change label: changelog.d/misc\n",
+		},
+		{
+			name:   "release fragments",
+			args:   []string{"-r", "minor"},
+			stdout: "0.2.0\n",
+			create: files{
+				".kcrc": `
+				[fragments]
+					dir = "changelog.d"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 0.1.0
+				`,
+				"changelog.d/added/1.md": "new feature\n",
+				"changelog.d/added/2.md": "another feature\n",
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 0.2.0 - {TEST_DATE}
+
+				### Added
+
+				- new feature
+				- another feature
+
+				## 0.1.0
+				`,
+				"changelog.d/added/1.md": "",
+				"changelog.d/added/2.md": "",
+			},
+		},
+		{
+			name: "check valid changelog",
+			args: []string{"--check"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				## [0.2.0] - 2020-01-02
+				### Added
+				- feature
+				## 0.1.0 - 2020-01-01
+				- initial release
+				[0.2.0]: https://example.com/0.2.0
+				`,
+			},
+		},
+		{
+			name: "check problems",
+			args: []string{"-k"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 0.1.0 - 2999-01-01
+				### Misc
+				- change
+				## [0.2.0] - 2020-01-01
+				## 0.1.0
+				- change
+				[1.0.0]: https://example.com/1.0.0
+				`,
+			},
+			stdout: `CHANGELOG.md:2: warning: release is dated in the future: 2999-01-01 [future-date]
+			CHANGELOG.md:3: unknown change label: "Misc" [unknown-label]
+			CHANGELOG.md:5: release heading references a missing link: 0.2.0 [missing-link]
+			CHANGELOG.md:5: warning: release has no changes: 0.2.0 [empty-release]
+			CHANGELOG.md:5: warning: release 0.2.0 must precede 0.1.0 [release-order]
+			CHANGELOG.md:6: duplicate release heading: 0.1.0 [duplicate-version]
+			CHANGELOG.md:8: release link (1.0.0) is missing a corresponding version heading [broken-link]
+			`,
+			stderr: "Found 4 problems.\n",
+		},
+		{
+			name: "check warnings",
+			args: []string{"-k"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0beta - 2020-01-01
+				- change
+				## 0.1.0 - 2020-01-02
+				- change
+				`,
+			},
+			stdout: `CHANGELOG.md:2: warning: version does not adhere to semver: 1.0.0beta [version-format]
+			CHANGELOG.md:4: warning: release 0.1.0 is dated after the newer release 1.0.0beta: 2020-01-02 > 2020-01-01 [date-order]
+			`,
+		},
+		{
+			name: "check strict",
+			args: []string{"-k", "--strict"},
+			create: files{
+				".kcrc": `
+				[check]
+					disable = ["date-order"]
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0beta - 2020-01-01
+				- change
+				## 0.1.0 - 2020-01-02
+				- change
+				`,
+			},
+			stdout: `CHANGELOG.md:2: warning: version does not adhere to semver: 1.0.0beta [version-format]
+			`,
+			stderr: "Found 1 problem.\n",
+		},
+		{
+			name: "fix",
+			args: []string{"--fix"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 0.1.0 - 2019/01/05
+				* first
+				* second
+				## 0.2.0 - 2019/02/01
+				### fixed
+				* a fix
+				### added
+				* a feature
+				[0.3.0]: https://example.com/stale
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 0.2.0 - 2019-02-01
+
+				### Added
+
+				- a feature
+
+				### Fixed
+
+				- a fix
+
+				## 0.1.0 - 2019-01-05
+
+				- first
+				- second
+				`,
+			},
+		},
+		{
+			name: "fix diff",
+			args: []string{"-F", "--diff"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 0.1.0 - 2019/01/05
+				* first
+				`,
+			},
+			stdout: `--- a/CHANGELOG.md
+			+++ b/CHANGELOG.md
+			@@ -1,3 +1,5 @@
+			 # Changelog
+			-## 0.1.0 - 2019/01/05
+			-* first
+			+
+			+## 0.1.0 - 2019-01-05
+			+
+			+- first
+			`,
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+				## 0.1.0 - 2019/01/05
+				* first
+				`,
+			},
+		},
+		{
+			name:   "fix canonical",
+			args:   []string{"-F"},
+			stderr: "No changes.\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 0.1.0
+
+				- first
+				`,
+			},
+		},
+		{
+			name: "change dry run",
+			args: []string{"-n", "fix", "a bug"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 0.1.0
+				`,
+			},
+			stdout: `--- a/CHANGELOG.md
+			+++ b/CHANGELOG.md
+			@@ -1,3 +1,9 @@
+			 # Changelog
+			 
+			+## Unreleased
+			+
+			+### Fixed
+			+
+			+- a bug
+			+
+			 ## 0.1.0
+			`,
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 0.1.0
+				`,
+			},
+		},
+		{
+			name: "unrelease dry run",
+			args: []string{"--dry-run", "-R"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 0.1.0
+
+				- a
+				`,
+			},
+			stdout: `--- a/CHANGELOG.md
+			+++ b/CHANGELOG.md
+			@@ -1,5 +1,5 @@
+			 # Changelog
+			 
+			-## 0.1.0
+			+## Unreleased
+			 
+			 - a
+			`,
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 0.1.0
+
+				- a
+				`,
+			},
+		},
+		{
+			name: "sort calver",
+			args: []string{"-t"},
+			create: files{
+				".kcrc": `
+				[versioning]
+					scheme = "calver"
+					format = "YY.MM.MICRO"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 25.12.0
+				## 26.9.1
+				## 26.10.0
+				## 26.9.0
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## 26.10.0
+
+				## 26.9.1
+
+				## 26.9.0
+
+				## 25.12.0
+				`,
+			},
+		},
+		{
+			name:   "show calver exact",
+			args:   []string{"-s", "26.1.1"},
+			stdout: "## 26.1.1\n",
+			create: files{
+				".kcrc": `
+				[versioning]
+					scheme = "calver"
+					format = "YY.MM.MICRO"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 26.1.10
+				## 26.1.1
+				`,
+			},
+		},
+		{
+			name:   "release calver bump type",
+			args:   []string{"-r", "minor"},
+			stderr: "Error: calver versions are derived from the date, not bumped: minor\n",
+			create: files{
+				".kcrc": `
+				[versioning]
+					scheme = "calver"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 2026.1.0
+				`,
+			},
+		},
+		{
+			name:   "invalid versioning scheme",
+			args:   []string{"-l"},
+			stderr: "Error: no such versioning scheme: ver, try: semver | calver\n",
+			create: files{
+				".kcrc": `
+				[versioning]
+					scheme = "ver"
+				`,
+				"CHANGELOG.md": `# Changelog
+				`,
+			},
+		},
+		{
+			name:   "list projects",
+			args:   []string{"--list-projects"},
+			stdout: "api  packages/api/CHANGELOG.md\nweb  packages/web/CHANGELOG.md\n",
+			create: files{
+				".kcrc": `
+				[changelogs.api]
+					path = "packages/api/CHANGELOG.md"
+				[changelogs.web]
+					path = "packages/web/CHANGELOG.md"
+					[changelogs.web.changes]
+						labels = ["Features", "Fixes"]
+				`,
+			},
+		},
+		{
+			name:   "list projects without projects",
+			args:   []string{"--list-projects"},
+			stderr: "No projects configured.\n",
+		},
+		{
+			name: "project detected from working directory",
+			args: []string{"added", "a"},
+			dir:  "packages/api/src",
+			create: files{
+				".kcrc": `
+				[changelogs.api]
+					path = "packages/api/CHANGELOG.md"
+				[changelogs.web]
+					path = "packages/web/CHANGELOG.md"
+					[changelogs.web.changes]
+						labels = ["Features", "Fixes"]
+				`,
+				"packages/api/src/main.go": "",
+				"packages/api/CHANGELOG.md": `# Changelog
+				## Unreleased
+				`,
+				"packages/web/CHANGELOG.md": `# Changelog
+				## Unreleased
+				`,
+			},
+			expect: files{
+				"packages/api/CHANGELOG.md": `# Changelog
+
+				## Unreleased
+
+				### Added
+
+				- a
+				`,
+				"packages/web/CHANGELOG.md": `# Changelog
+				## Unreleased
+				`,
+			},
+		},
+		{
+			name: "project option",
+			args: []string{"-P", "w", "feat", "a"},
+			create: files{
+				".kcrc": `
+				[changelogs.api]
+					path = "packages/api/CHANGELOG.md"
+				[changelogs.web]
+					path = "packages/web/CHANGELOG.md"
+					[changelogs.web.changes]
+						labels = ["Features", "Fixes"]
+				`,
+				"packages/web/CHANGELOG.md": `# Changelog
+				## Unreleased
+				`,
+			},
+			expect: files{
+				"packages/web/CHANGELOG.md": `# Changelog
+
+				## Unreleased
+
+				### Features
+
+				- a
+				`,
+			},
+		},
+		{
+			name:   "project option no such project",
+			args:   []string{"-P", "cli", "-s"},
+			stderr: "Error: no such project: cli, try: api | web\n",
+			create: files{
+				".kcrc": `
+				[changelogs.api]
+					path = "packages/api/CHANGELOG.md"
+				[changelogs.web]
+					path = "packages/web/CHANGELOG.md"
+					[changelogs.web.changes]
+						labels = ["Features", "Fixes"]
+				`,
+			},
+		},
+		{
+			name:   "project not detected",
+			args:   []string{"-s"},
+			stderr: "No changelog found. Select a project via --project: api | web\n",
+			create: files{
+				".kcrc": `
+				[changelogs.api]
+					path = "packages/api/CHANGELOG.md"
+				[changelogs.web]
+					path = "packages/web/CHANGELOG.md"
+					[changelogs.web.changes]
+						labels = ["Features", "Fixes"]
+				`,
+			},
+		},
+		{
+			name: "aggregate",
+			args: []string{"-A", "--since", "2020-10-01"},
+			stdout: `## api
+
+			Release: 1.1.0 (2020-10-10).
+
+			### Added
+
+			- a
+
+			### Fixed
+
+			- b
+
+			## web
+
+			Releases: 2.0.0 (2020-10-12), 1.9.0 (2020-10-01).
+
+			### Features
+
+			- e
+
+			### Fixes
+
+			- d
+			`,
+			create: files{
+				".kcrc": `
+				[changelogs.api]
+					path = "api/CHANGELOG.md"
+				[changelogs.web]
+					path = "web/CHANGELOG.md"
+					[changelogs.web.changes]
+						labels = ["Features", "Fixes"]
+				`,
+				"api/CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.1.0 - 2020-10-10
+				### Added
+				- a
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- c
+				`,
+				"web/CHANGELOG.md": `# Changelog
+				## 2.0.0 - 2020-10-12
+				### Fixes
+				- d
+				## 1.9.0 - 2020-10-01
+				### Features
+				- e
+				`,
+			},
+		},
+		{
+			name: "aggregate json",
+			args: []string{"-A", "-f", "json", "--until", "2020-01-01"},
+			stdout: `[
+			  {
+			    "name": "api",
+			    "changelog": "api/CHANGELOG.md",
+			    "releases": [
+			      {
+			        "version": "1.0.0",
+			        "unreleased": false,
+			        "date": "2020-01-01",
+			        "yanked": false,
+			        "changes": 1
+			      }
+			    ],
+			    "changes": [
+			      {
+			        "label": "Added",
+			        "items": [
+			          "c"
+			        ]
+			      }
+			    ]
+			  }
+			]
+			`,
+			create: files{
+				".kcrc": `
+				[changelogs.api]
+					path = "api/CHANGELOG.md"
+				[changelogs.web]
+					path = "web/CHANGELOG.md"
+					[changelogs.web.changes]
+						labels = ["Features", "Fixes"]
+				`,
+				"api/CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.1.0 - 2020-10-10
+				### Added
+				- a
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- c
+				`,
+				"web/CHANGELOG.md": `# Changelog
+				## 2.0.0 - 2020-10-12
+				### Fixes
+				- d
+				## 1.9.0 - 2020-10-01
+				### Features
+				- e
+				`,
+			},
+		},
+		{
+			name:   "aggregate no matches",
+			args:   []string{"-A", "--since", "2021-01-01"},
+			stderr: "No matches.\n",
+			create: files{
+				".kcrc": `
+				[changelogs.api]
+					path = "api/CHANGELOG.md"
+				[changelogs.web]
+					path = "web/CHANGELOG.md"
+					[changelogs.web.changes]
+						labels = ["Features", "Fixes"]
+				`,
+				"api/CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.1.0 - 2020-10-10
+				### Added
+				- a
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- c
+				`,
+				"web/CHANGELOG.md": `# Changelog
+				## 2.0.0 - 2020-10-12
+				### Fixes
+				- d
+				## 1.9.0 - 2020-10-01
+				### Features
+				- e
+				`,
+			},
+		},
+		{
+			name:   "aggregate invalid date",
+			args:   []string{"-A", "--since", "yesterday"},
+			stderr: "Error: invalid --since date: yesterday, expected YYYY-MM-DD\n",
+		},
+		{
+			name: "show label since until",
+			args: []string{"-s", "--label", "fix", "--since", "2020-07-01", "--until", "2020-09-30"},
+			stdout: `## 1.2.0 - 2020-09-15
+
+			### Fixed
+
+			- c by @alice
+
+			## 1.1.0 - 2020-07-01
+
+			### Fixed
+
+			- b
+			`,
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Added
+				- e by @alice
+				## 1.2.0 - 2020-09-15
+				### Added
+				- d
+				### Fixed
+				- c by @alice
+				## 1.1.0 - 2020-07-01
+				Thanks, @alice!
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- a
+				`,
+			},
+		},
+		{
+			name: "show grep",
+			args: []string{"-s", "--grep", "@alice"},
+			stdout: `## Unreleased
+
+			### Added
+
+			- e by @alice
+
+			## 1.2.0 - 2020-09-15
+
+			### Fixed
+
+			- c by @alice
+
+			## 1.1.0 - 2020-07-01
+
+			Thanks, @alice!
+			`,
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Added
+				- e by @alice
+				## 1.2.0 - 2020-09-15
+				### Added
+				- d
+				### Fixed
+				- c by @alice
+				## 1.1.0 - 2020-07-01
+				Thanks, @alice!
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- a
+				`,
+			},
+		},
+		{
+			name:   "show grep pattern",
+			args:   []string{"-s", "--grep", "a", "1.0"},
+			stdout: "## 1.0.0 - 2020-01-01\n\n### Added\n\n- a\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Added
+				- e by @alice
+				## 1.2.0 - 2020-09-15
+				### Added
+				- d
+				### Fixed
+				- c by @alice
+				## 1.1.0 - 2020-07-01
+				Thanks, @alice!
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- a
+				`,
+			},
+		},
+		{
+			name:   "show label no matches",
+			args:   []string{"-s", "--label", "removed"},
+			stderr: "No matches.\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Added
+				- e by @alice
+				## 1.2.0 - 2020-09-15
+				### Added
+				- d
+				### Fixed
+				- c by @alice
+				## 1.1.0 - 2020-07-01
+				Thanks, @alice!
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- a
+				`,
+			},
+		},
+		{
+			name:   "show unknown label",
+			args:   []string{"-s", "--label", "foo"},
+			stderr: "Error: no such change label: foo, try: Added | Removed | Changed | Security | Fixed | Deprecated\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Added
+				- e by @alice
+				## 1.2.0 - 2020-09-15
+				### Added
+				- d
+				### Fixed
+				- c by @alice
+				## 1.1.0 - 2020-07-01
+				Thanks, @alice!
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- a
+				`,
+			},
+		},
+		{
+			name:   "list since",
+			args:   []string{"-l", "--since", "2020-07-01"},
+			stdout: "1.2.0\n1.1.0\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Added
+				- e by @alice
+				## 1.2.0 - 2020-09-15
+				### Added
+				- d
+				### Fixed
+				- c by @alice
+				## 1.1.0 - 2020-07-01
+				Thanks, @alice!
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- a
+				`,
+			},
+		},
+		{
+			name:   "list all label",
+			args:   []string{"-L", "--label", "add"},
+			stdout: "\"Unreleased\" (1 change)\n1.2.0 (1 change)\n1.0.0 (1 change)\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				### Added
+				- e by @alice
+				## 1.2.0 - 2020-09-15
+				### Added
+				- d
+				### Fixed
+				- c by @alice
+				## 1.1.0 - 2020-07-01
+				Thanks, @alice!
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- a
+				`,
+			},
+		},
+		{
+			name:   "list version range",
+			args:   []string{"-l", ">=1.4.0 <2.0.0"},
+			stdout: "2.0.0-rc.1\n1.9.0\n1.4.0\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 2.0.0
+				## 2.0.0-rc.1
+				## 1.9.0
+				## 1.4.0
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "list caret range",
+			args:   []string{"-l", "^1.2"},
+			stdout: "1.9.0\n1.4.0\n1.2.3\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 2.0.0
+				## 2.0.0-rc.1
+				## 1.9.0
+				## 1.4.0
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "show dotted range",
+			args:   []string{"-s", "1.9.0..2.0.0-rc.1"},
+			stdout: "## 2.0.0-rc.1\n\n## 1.9.0\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 2.0.0
+				## 2.0.0-rc.1
+				## 1.9.0
+				## 1.4.0
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name:   "show prefixed range",
+			args:   []string{"-s", "~v1.4"},
+			stdout: "## v1.4.0\n",
+			create: files{
+				".kcrc": `
+				[versioning]
+					prefix = "v"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## v1.9.0
+				## v1.4.0
+				`,
+			},
+		},
+		{
+			name:   "delete invalid range",
+			args:   []string{"-d", ">=1.x"},
+			stderr: "Error: invalid version in range: 1.x\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 2.0.0
+				## 2.0.0-rc.1
+				## 1.9.0
+				## 1.4.0
+				## 1.2.3
+				`,
+			},
+		},
+		{
+			name: "between",
+			args: []string{"-b", "1.2.0", "1.9.0"},
+			stdout: `## Upgrading from 1.2.0 to 1.9.0
+
+			Upgrade with care.
+
+			### Added
+
+			- c
+
+			### Fixed
+
+			- b
+			- a
+			`,
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.9.0 - 2020-10-10
+				### Added
+				- c
+				### Fixed
+				- b
+				## 1.4.0 - 2020-05-01
+				Upgrade with care.
+				### Fixed
+				- b
+				- a
+				## 1.2.0 - 2020-01-01
+				### Added
+				- init
+				`,
+			},
+		},
+		{
+			name: "between annotate",
+			args: []string{"-b", "--annotate", "--label", "fix", "1.2.0", "1.9.0"},
+			stdout: `## Upgrading from 1.2.0 to 1.9.0
+
+			### Fixed
+
+			- b (1.9.0, 1.4.0)
+			- a (1.4.0)
+			`,
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.9.0 - 2020-10-10
+				### Added
+				- c
+				### Fixed
+				- b
+				## 1.4.0 - 2020-05-01
+				Upgrade with care.
+				### Fixed
+				- b
+				- a
+				## 1.2.0 - 2020-01-01
+				### Added
+				- init
+				`,
+			},
+		},
+		{
+			name:   "between reversed",
+			args:   []string{"-b", "1.9.0", "1.2.0"},
+			stderr: "Error: 1.9.0 must precede 1.2.0\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.9.0 - 2020-10-10
+				### Added
+				- c
+				### Fixed
+				- b
+				## 1.4.0 - 2020-05-01
+				Upgrade with care.
+				### Fixed
+				- b
+				- a
+				## 1.2.0 - 2020-01-01
+				### Added
+				- init
+				`,
+			},
+		},
+		{
+			name:   "between no matches",
+			args:   []string{"-b", "1.9.0", "2.0.0"},
+			stderr: "No matches.\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.9.0 - 2020-10-10
+				### Added
+				- c
+				### Fixed
+				- b
+				## 1.4.0 - 2020-05-01
+				Upgrade with care.
+				### Fixed
+				- b
+				- a
+				## 1.2.0 - 2020-01-01
+				### Added
+				- init
+				`,
+			},
+		},
+		{
+			name: "stats",
+			args: []string{"-S"},
+			stdout: `Release       Date        Changes
+			"Unreleased"              1
+			1.2.0         2020-03-01  2
+			1.1.0         2020-01-31  2
+			1.0.0         2020-01-01  1
+
+			Label        Changes
+			(unlabeled)  1
+			Added        2
+			Fixed        3
+			Total        6
+
+			Cadence:             mean 30 days, median 30 days
+			Longest gap:         30 days (1.0.0 to 1.1.0)
+			Time in Unreleased:  n/a
+
+			Mention  Count
+			@alice   2
+			@bob     1
+			@carol   1
+			`,
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- x by @carol
+				## 1.2.0 - 2020-03-01
+				### Added
+				- a by @bob
+				### Fixed
+				- b by [@alice](https://example.com/alice)
+				## 1.1.0 - 2020-01-31
+				Thanks, @alice!
+				### Fixed
+				- c
+				- d
+				## 1.0.0 - 2020-01-01
+				### Added
+				- e
+				`,
+			},
+		},
+		{
+			name: "stats json",
+			args: []string{"-S", "-f", "json"},
+			stdout: `{
+			  "releases": [
+			    {
+			      "version": "1.1.0",
+			      "unreleased": false,
+			      "date": "2020-01-31",
+			      "yanked": false,
+			      "changes": 1
+			    },
+			    {
+			      "version": "1.0.0",
+			      "unreleased": false,
+			      "date": "2020-01-01",
+			      "yanked": false,
+			      "changes": 1
+			    }
+			  ],
+			  "labels": [
+			    {
+			      "label": "Added",
+			      "changes": 2
+			    }
+			  ],
+			  "cadence": {
+			    "mean_days": 30,
+			    "median_days": 30
+			  },
+			  "longest_gap": {
+			    "from": "1.0.0",
+			    "to": "1.1.0",
+			    "days": 30
+			  },
+			  "unreleased_time": null,
+			  "mentions": []
+			}
+			`,
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 1.1.0 - 2020-01-31
+				### Added
+				- a
+				## 1.0.0 - 2020-01-01
+				### Added
+				- b
+				`,
+			},
+		},
+		{
+			name: "export html",
+			args: []string{"-x", "html"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.0.0 - 2020-01-01
+				- init
+				`,
+			},
+			stdout: "IGNORE",
+		},
+		{
+			name: "export html template",
+			args: []string{"-x", "h", "1.*"},
+			create: files{
+				".kcrc": `
+				[links]
+					release = "rel/{PREVIOUS}...{CURRENT}"
+					mention = "users/{MENTION}"
+				[export.templates]
+					html = "export.tmpl"
+				`,
+				"export.tmpl": `{{ range .Releases -}}
+				<h2 id="{{ anchor .Version }}"><a href="{{ .Link }}">{{ .Version }}</a></h2>
+				{{ markdown .Note }}
+				{{ range .Changes }}{{ range .Items }}<li>{{ inline . }}</li>
+				{{ end }}{{ end }}{{ end -}}`,
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.1.0-Beta+Exp - 2020-02-01
+				Thanks, @user!
+				### Added
+				- **New** <flag> via ` + "`-x`" + `
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				### Added
+				- init
+				## 0.1.0
+				### Added
+				- a
+				`,
+			},
+			stdout: `<h2 id="1.1.0-beta-exp"><a href="rel/1.0.0...1.1.0-Beta&#43;Exp">1.1.0-Beta&#43;Exp</a></h2>
+			<p>Thanks, <a href="users/user">@user</a>!</p>
+			<li><strong>New</strong> &lt;flag&gt; via <code>-x</code></li>
+			<li>b</li>
+			<h2 id="1.0.0"><a href="rel/0.1.0...1.0.0">1.0.0</a></h2>
+
+			<li>init</li>
+			`,
+		},
+		{
+			name: "export unknown format",
+			args: []string{"-x", "pdf"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0 - 2020-01-01
+				- init
+				`,
+			},
+			stderr: "Error: no such export format: pdf, try: html | atom | rss | debian | rpm\n",
+		},
+		{
+			name: "export no matches",
+			args: []string{"-x", "html", "2.*"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0 - 2020-01-01
+				- init
+				`,
+			},
+			stderr: "No matches.\n",
+		},
+		{
+			name: "export atom",
+			args: []string{"-x", "atom"},
+			create: files{
+				".kcrc": `
+				[links]
+					release = "https://example.com/compare/{PREVIOUS}...{CURRENT}"
+				[feed]
+					title = "Releases & more"
+					id = "urn:kc:example"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.1.0 - 2020-02-01
+				### Added
+				- <b>
+				## 1.0.0
+				- init
+				`,
+			},
+			stdout: `<?xml version="1.0" encoding="utf-8"?>
+			<feed xmlns="http://www.w3.org/2005/Atom">
+			<title>Releases &amp; more</title>
+			<id>urn:kc:example</id>
+			<updated>2020-02-01T00:00:00Z</updated>
+			<entry>
+			<title>1.1.0</title>
+			<id>urn:kc:example#1.1.0</id>
+			<link href="https://example.com/compare/1.0.0...1.1.0"/>
+			<updated>2020-02-01T00:00:00Z</updated>
+			<content type="html">&lt;h3&gt;Added&lt;/h3&gt;
+			&lt;ul&gt;
+			&lt;li&gt;&amp;lt;b&amp;gt;&lt;/li&gt;
+			&lt;/ul&gt;</content>
+			</entry>
+			<entry>
+			<title>1.0.0</title>
+			<id>urn:kc:example#1.0.0</id>
+			<updated>2020-02-01T00:00:00Z</updated>
+			<content type="html">&lt;ul&gt;
+			&lt;li&gt;init&lt;/li&gt;
+			&lt;/ul&gt;</content>
+			</entry>
+			</feed>
+			`,
+		},
+		{
+			name: "export rss",
+			args: []string{"-x", "rss"},
+			create: files{
+				".kcrc": `
+				[feed]
+					url = "https://example.com/changelog.html"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0 - 2020-01-01 [YANKED]
+				Thanks, @user!
+				`,
+			},
+			stdout: `<?xml version="1.0" encoding="utf-8"?>
+			<rss version="2.0">
+			<channel>
+			<title>Changelog</title>
+			<link>https://example.com/changelog.html</link>
+			<description>Changelog</description>
+			<lastBuildDate>Wed, 01 Jan 2020 00:00:00 +0000</lastBuildDate>
+			<item>
+			<title>1.0.0 [YANKED]</title>
+			<link>https://example.com/changelog.html#1.0.0</link>
+			<guid isPermaLink="false">https://example.com/changelog.html#1.0.0</guid>
+			<pubDate>Wed, 01 Jan 2020 00:00:00 +0000</pubDate>
+			<description>&lt;p&gt;Thanks, @user!&lt;/p&gt;</description>
+			</item>
+			</channel>
+			</rss>
+			`,
+		},
+		{
+			name: "export rss missing url",
+			args: []string{"-x", "rss"},
+			create: files{
+				".kcrc": `
+				[feed]
+					id = "urn:kc:example"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0 - 2020-01-01
+				- init
+				`,
+			},
+			stderr: "Error: missing feed.url\n",
+		},
+		{
+			name: "export atom missing id",
+			args: []string{"-x", "atom"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0 - 2020-01-01
+				- init
+				`,
+			},
+			stderr: "Error: missing feed.id or feed.url\n",
+		},
+		{
+			name: "export debian",
+			args: []string{"-x", "debian"},
+			create: files{
+				".kcrc": `
+				[versioning]
+					prefix = "v"
+				[packaging]
+					name = "kc"
+					maintainer = "Jane Doe"
+					email = "jane@example.com"
+					distribution = "focal"
+					revision = "0ubuntu1"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## v1.1.0 - 2020-02-01
+				### Added
+				- a
+				- multi
+				  line
+				### Fixed
+				- b
+				## v1.0.0-rc.1 - 2020-01-01
+				- init
+				## v0.1.0 - 2019-12-01
+				`,
+			},
+			stdout: `kc (1.1.0-0ubuntu1) focal; urgency=medium
+
+			  * Added:
+			    - a
+			    - multi
+			      line
+			  * Fixed:
+			    - b
+
+			 -- Jane Doe <jane@example.com>  Sat, 01 Feb 2020 00:00:00 +0000
+
+			kc (1.0.0~rc.1-0ubuntu1) focal; urgency=medium
+
+			  * init
+
+			 -- Jane Doe <jane@example.com>  Wed, 01 Jan 2020 00:00:00 +0000
+
+			kc (0.1.0-0ubuntu1) focal; urgency=medium
+
+			  * New upstream release.
+
+			 -- Jane Doe <jane@example.com>  Sun, 01 Dec 2019 00:00:00 +0000
+			`,
+		},
+		{
+			name: "export rpm",
+			args: []string{"-x", "rpm", "1.*"},
+			create: files{
+				".kcrc": `
+				[packaging]
+					maintainer = "Jane Doe"
+					email = "jane@example.com"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 1.1.0 - 2020-02-01
+				### Added
+				- a
+				### Fixed
+				- b
+				## 1.0.0 - 2020-01-01
+				- init
+				## 0.1.0
+				`,
+			},
+			stdout: `* Sat Feb 01 2020 Jane Doe <jane@example.com> - 1.1.0-1
+			- Added:
+			  - a
+			- Fixed:
+			  - b
+
+			* Wed Jan 01 2020 Jane Doe <jane@example.com> - 1.0.0-1
+			- init
+			`,
+		},
+		{
+			name: "export rpm macros",
+			args: []string{"-x", "rpm"},
+			create: files{
+				".kcrc": `
+				[packaging]
+					maintainer = "Jane %{Doe}"
+					email = "jane@example.com"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0 - 2020-01-01
+				### Fixed
+				- 50% fewer %{name} macros
+				- %changelog
+				  section
+				`,
+			},
+			stdout: `* Wed Jan 01 2020 Jane %%{Doe} <jane@example.com> - 1.0.0-1
+			- Fixed:
+			  - 50%% fewer %%{name} macros
+			  - %%changelog
+			    section
+			`,
+		},
+		{
+			name: "export rpm undated",
+			args: []string{"-x", "rpm"},
+			create: files{
+				".kcrc": `
+				[packaging]
+					maintainer = "Jane Doe"
+					email = "jane@example.com"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0 - 2020-01-01
+				- init
+				## 0.1.0
+				`,
+			},
+			stderr: "Error: 0.1.0: missing release date\n",
+		},
+		{
+			name: "export debian missing name",
+			args: []string{"-x", "debian"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0 - 2020-01-01
+				- init
+				`,
+			},
+			stderr: "Error: missing packaging.name\n",
+		},
+		{
+			name: "import towncrier",
+			args: []string{"-I", "town", "NEWS.rst"},
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- wip
+				## 1.0.0 - 2020-01-01
+				### Added
+				- init
+				## 0.1.0 - 2019-12-01
+				### Added
+				- a
+				`,
+				"NEWS.rst": `1.1.0 (2020-02-01)
+				==================
+
+				Features
+				--------
+
+				- new
+
+				Improved Documentation
+				----------------------
+
+				- docs
+
+				1.0.0 (2020-01-01)
+				==================
+
+				Features
+				--------
+
+				- init
+
+				0.1.0 (2019-12-01)
+				==================
+
+				Features
+				--------
+
+				- b
+				`,
+			},
+			stderr: "Skipped 0.1.0, which differs from the existing release.\n",
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+			## Unreleased
+
+			- wip
+
+			## 1.1.0 - 2020-02-01
+
+			### Added
+
+			- new
+
+			### Changed
+
+			- docs
+
+			## 1.0.0 - 2020-01-01
+
+			### Added
+
+			- init
+
+			## 0.1.0 - 2019-12-01
+
+			### Added
+
+			- a
+			`,
+			},
+		},
+		{
+			name:  "import github stdin",
+			args:  []string{"--import", "github", "-"},
+			stdin: `[{"tag_name": "v1.0.0", "published_at": "2020-01-01T12:00:00Z", "body": "## Bug Fixes\r\n* a"}]`,
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+			## Unreleased
+
+			## 1.0.0 - 2020-01-01
+
+			### Fixed
+
+			- a
+			`,
+			},
+		},
+		{
+			name:  "import no changes",
+			args:  []string{"--import", "debian", "-"},
+			stdin: "kc (1.0.0-1) unstable; urgency=low\n\n  * a\n\n -- J <j@example.com>  Wed, 01 Jan 2020 10:00:00 +0000\n",
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				## 1.0.0 - 2020-01-01
+				- a
+				`,
+			},
+			stderr: "No changes.\n",
+		},
+		{
+			name:  "import unknown category",
+			args:  []string{"--import", "github", "-"},
+			stdin: `[{"tag_name": "1.0.0", "body": "## Chores\n* a"}]`,
+			create: files{
+				"CHANGELOG.md": `# Changelog
+				`,
+			},
+			stderr: "Error: 1.0.0: unknown category: \"Chores\" (map it via import.labels)\n",
+		},
+		{
+			name: "init changelog user template",
+			args: []string{"-i", "changelog", "team"},
+			create: files{
+				".config/kc/templates/changelog/Team.tmpl": `# {{ or (env "KC_UNSET_VARIABLE") "Team" }} Changelog
+
+				Started on {{ date }}.
+
+				## Unreleased
+				`,
+			},
+			stdout: `# Team Changelog
+
+			Started on {TEST_DATE}.
+
+			## Unreleased
+			`,
+		},
+		{
+			name: "init changelog local template",
+			args: []string{"-i", "changelog"},
+			dir:  "a/b",
+			create: files{
+				".config/kc/templates/changelog/default.tmpl": `# User`,
+				".kc/templates/changelog/default.tmpl":        `# Local`,
+				".git/HEAD":                                   "ref: refs/heads/master\n",
+				"a/b/.keep":                                   "",
+			},
+			stdout: "# Local\n",
+		},
+		{
+			name: "print changelog templates",
+			args: []string{"-p", "changelog", "templates"},
+			create: files{
+				".config/kc/templates/changelog/team.tmpl": `# Team`,
+				".config/kc/templates/changelog/notes.txt": `# Ignored`,
+				".kc/templates/config/local.tmpl":          `[changes]`,
+			},
+			stdout: "default\nkacl\nsemver\nteam\n",
+		},
+		{
+			name: "print changelog path with broken templates",
+			args: []string{"-p", "changelog", "path"},
+			create: files{
+				".config/kc/templates/changelog": `not a directory`,
+				"CHANGELOG.md":                   `# Changelog`,
+			},
+			stdout: "CHANGELOG.md\n",
+		},
+		{
+			name: "print config templates",
+			args: []string{"-p", "config", "templates", "local"},
+			create: files{
+				".kc/templates/config/local.tmpl": `[changes]
+				  labels = ["Added", "Fixed"]
+				`,
+			},
+			stdout: `[changes]
+			  labels = ["Added", "Fixed"]
+			`,
+		},
+		{
+			name:   "release version prefix",
+			args:   []string{"-r", "min"},
+			stdout: "v1.3.0\n",
+			create: files{
+				".kcrc": `
+				[versioning]
+					prefix = "v"
+				[links]
+					release = "https://example.com/compare/{PREVIOUS}...{CURRENT}"
+					initial-release = "https://example.com/tag/{CURRENT}"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## v1.2.3
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## [v1.3.0] - {TEST_DATE}
+
+				- a
+
+				## [v1.2.3]
+
+				[v1.3.0]: https://example.com/compare/v1.2.3...v1.3.0
+				[v1.2.3]: https://example.com/tag/v1.2.3
+				`,
+			},
+		},
+		{
+			name:   "release tag template",
+			args:   []string{"-r"},
+			stdout: "1.0.1\n",
+			create: files{
+				".kcrc": `
+				[versioning]
+					prefix = "v"
+					tag = "api/{PREFIX}{VERSION}"
+				[links]
+					release = "https://example.com/compare/{PREVIOUS}...{CURRENT}"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## Unreleased
+				- a
+				## 1.0.0
+				`,
+			},
+			expect: files{
+				"CHANGELOG.md": `# Changelog
+
+				## [1.0.1] - {TEST_DATE}
+
+				- a
+
+				## 1.0.0
+
+				[1.0.1]: https://example.com/compare/api/v1.0.0...api/v1.0.1
+				`,
+			},
+		},
+		{
+			name:   "show version prefix",
+			args:   []string{"-s", "1.2.3"},
+			stdout: "## v1.2.3\n",
+			create: files{
+				".kcrc": `
+				[versioning]
+					prefix = "v"
+				`,
+				"CHANGELOG.md": `# Changelog
+				## v1.2.30
+				## v1.2.3
+				## 1.2.0
+				`,
+			},
+		},
+		{
+			name: "change label prefix",
+			args: []string{"a"},
 			create: files{
 				"CHANGELOG.md": `# Changelog`,
 			},
@@ -1280,8 +3537,15 @@ func TestInvoke(t *testing.T) {
 			defer os.RemoveAll(dir)
 			defer cd(t, cd(t, dir))
 
+			// Isolate the test from the user's templates.
+			defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+			os.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, ".config"))
+
 			// Populate the directory with whatever test files we need.
 			for name, text := range test.create {
+				if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+					t.Fatal(err)
+				}
 				if err := ioutil.WriteFile(name, []byte(noTabs(text)), 0644); err != nil {
 					t.Fatal(err)
 				}
@@ -1310,14 +3574,18 @@ func TestInvoke(t *testing.T) {
 
 			// Invoke, but ignore the error, which is already printed to
 			// stderr.
+			if test.dir != "" {
+				cd(t, test.dir)
+			}
 			inv.invoke(test.args)
+			cd(t, dir)
 
 			// Group actual and expected outputs/files.
+			now := time.Now().Format(iso8601)
 			exp := map[string]string{
-				"stdout": noTabs(test.stdout),
+				"stdout": noTabs(strings.ReplaceAll(test.stdout, "{TEST_DATE}", now)),
 				"stderr": noTabs(test.stderr),
 			}
-			now := time.Now().Format(iso8601)
 			for name, text := range test.expect {
 				text = strings.ReplaceAll(text, "{TEST_DATE}", now)
 				text = noTabs(text)
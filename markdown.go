@@ -0,0 +1,76 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	reMarkdownCode     = regexp.MustCompile("^`([^`]+)`")
+	reMarkdownLink     = regexp.MustCompile(`^\[([^\]]+)\]\(((?:[^()\s]|\([^()\s]*\))+)\)`)
+	reMarkdownAutolink = regexp.MustCompile(`^<((?:https?|mailto):[^>\s]+)>`)
+	reMarkdownStrong   = regexp.MustCompile(`^(?:\*\*|__)(\S(?:.*?\S)?)(?:\*\*|__)`)
+	reMarkdownEmphasis = regexp.MustCompile(`^\*(\S(?:.*?\S)?)\*`)
+	reMarkdownURL      = regexp.MustCompile(`^(?i:https?://|mailto:|[^:]*$)`)
+	reMarkdownPara     = regexp.MustCompile(`\n\s*\n`)
+)
+
+// markdownInline renders the inline Markdown of s as HTML, which is limited to
+// code spans, links, autolinks, and strong and emphasized text. Everything
+// else, including raw HTML, is escaped.
+func markdownInline(s string) string {
+	var buf strings.Builder
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		var m []string
+		switch rest[0] {
+		case '`':
+			if m = reMarkdownCode.FindStringSubmatch(rest); m != nil {
+				buf.WriteString("<code>" + html.EscapeString(m[1]) + "</code>")
+			}
+		case '[':
+			if m = reMarkdownLink.FindStringSubmatch(rest); m != nil {
+				buf.WriteString(markdownLink(m[2], markdownInline(m[1])))
+			}
+		case '<':
+			if m = reMarkdownAutolink.FindStringSubmatch(rest); m != nil {
+				buf.WriteString(markdownLink(m[1], html.EscapeString(m[1])))
+			}
+		case '*', '_':
+			if m = reMarkdownStrong.FindStringSubmatch(rest); m != nil {
+				buf.WriteString("<strong>" + markdownInline(m[1]) + "</strong>")
+			} else if m = reMarkdownEmphasis.FindStringSubmatch(rest); m != nil {
+				buf.WriteString("<em>" + markdownInline(m[1]) + "</em>")
+			}
+		}
+		if m != nil {
+			i += len(m[0])
+			continue
+		}
+		buf.WriteString(html.EscapeString(rest[:1]))
+		i++
+	}
+	return buf.String()
+}
+
+// markdownLink returns an HTML link to url, unless its scheme is unsafe, in
+// which case only the (rendered) text is returned.
+func markdownLink(url, text string) string {
+	if !reMarkdownURL.MatchString(url) {
+		return text
+	}
+	return `<a href="` + html.EscapeString(url) + `">` + text + "</a>"
+}
+
+// markdown renders s as a series of paragraphs, which are separated by blank
+// lines and consist of inline Markdown.
+func markdown(s string) string {
+	var paras []string
+	for _, p := range reMarkdownPara.Split(strings.TrimSpace(s), -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			paras = append(paras, "<p>"+markdownInline(p)+"</p>")
+		}
+	}
+	return strings.Join(paras, "\n")
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestMarkdownInline(t *testing.T) {
+	for _, test := range []struct {
+		in, exp string
+	}{
+		{"plain", "plain"},
+		{"a <b> & c", "a &lt;b&gt; &amp; c"},
+		{"run `kc <x>`", "run <code>kc &lt;x&gt;</code>"},
+		{"**bold** and __bold__", "<strong>bold</strong> and <strong>bold</strong>"},
+		{"*em* and * not em *", "<em>em</em> and * not em *"},
+		{"snake_case_name", "snake_case_name"},
+		{"[**docs**](https://example.com/a_(b))", `<a href="https://example.com/a_(b)"><strong>docs</strong></a>`},
+		{"[rel](../CHANGELOG.md)", `<a href="../CHANGELOG.md">rel</a>`},
+		{"[bad](javascript:alert(1))", "bad"},
+		{"<https://example.com/?a=1&b=2>", `<a href="https://example.com/?a=1&amp;b=2">https://example.com/?a=1&amp;b=2</a>`},
+		{"`unclosed", "`unclosed"},
+	} {
+		if got := markdownInline(test.in); got != test.exp {
+			t.Errorf("%q: expected %q, got %q", test.in, test.exp, got)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	in := "\nFirst *line*\nstill first.\n\n  \nSecond.\n"
+	exp := "<p>First <em>line</em>\nstill first.</p>\n<p>Second.</p>"
+	if got := markdown(in); got != exp {
+		t.Errorf("expected %q, got %q", exp, got)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// project configures the changelog of a single component in a repository
+// that holds several, such as a monorepo. Its tables override those of the
+// config that defines it, which act as shared defaults.
+type project struct {
+	Path    string            `toml:"path"`
+	Links   map[string]string `toml:"links,omitempty"`
+	Changes struct {
+		Labels []string `toml:"labels,omitempty"`
+	} `toml:"changes,omitempty"`
+	Versioning struct {
+		Scheme string `toml:"scheme,omitempty"`
+		Format string `toml:"format,omitempty"`
+		Prefix string `toml:"prefix,omitempty"`
+		Tag    string `toml:"tag,omitempty"`
+	} `toml:"versioning,omitempty"`
+}
+
+func (c *config) projectNames() []string {
+	names := make([]string, 0, len(c.Changelogs))
+	for name := range c.Changelogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// projectPath returns the path of the changelog of p, which is relative to
+// the directory of the config file unless it is absolute.
+func (c *config) projectPath(p *project) string {
+	if filepath.IsAbs(p.Path) {
+		return p.Path
+	}
+	return filepath.Join(filepath.Dir(c.path), p.Path)
+}
+
+// project returns the config of the project called name, which may be
+// specified as a prefix.
+func (c *config) project(name string) (*config, error) {
+	if len(c.Changelogs) == 0 {
+		return nil, warnf("No projects configured.")
+	}
+	name, err := prefix(name).matchAs(c.projectNames(), "project")
+	if err != nil {
+		return nil, err
+	}
+	p := c.Changelogs[name]
+	if p.Path == "" {
+		return nil, fmt.Errorf("project %s: missing changelog path", name)
+	}
+	cfg := *c
+	cfg.Links = make(map[string]string, len(c.Links))
+	for typ, tmpl := range c.Links {
+		cfg.Links[typ] = tmpl
+	}
+	other := newConfig()
+	other.Links = p.Links
+	other.Changes = p.Changes
+	other.Versioning = p.Versioning
+	if err := cfg.merge(other); err != nil {
+		return nil, err
+	}
+	if err := cfg.initVersioning(); err != nil {
+		return nil, fmt.Errorf("project %s: %s", name, err)
+	}
+	cfg.projectName = name
+	cfg.changelog = c.projectPath(p)
+	return &cfg, nil
+}
+
+// detectProject returns the name of the project whose changelog directory
+// contains dir. If several do, the innermost one wins.
+func (c *config) detectProject(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	var match, matchDir string
+	for _, name := range c.projectNames() {
+		pdir, err := filepath.Abs(filepath.Dir(c.projectPath(c.Changelogs[name])))
+		if err != nil {
+			continue
+		}
+		if dir != pdir && !strings.HasPrefix(dir, pdir+string(filepath.Separator)) {
+			continue
+		}
+		if len(pdir) > len(matchDir) {
+			match, matchDir = name, pdir
+		}
+	}
+	return match, match != ""
+}
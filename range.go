@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionRange is a disjunction of comparator sets, each of which is
+// a conjunction of comparators, as in ">=1.2.0 <2.0.0 || >=3.0.0".
+type versionRange [][]comparator
+
+type comparator struct {
+	op  string // one of <, <=, >, >=, =
+	ver string
+}
+
+func (c comparator) match(s versionScheme, ver string) bool {
+	n := s.compare(ver, c.ver)
+	switch c.op {
+	case "<":
+		return n < 0
+	case "<=":
+		return n <= 0
+	case ">":
+		return n > 0
+	case ">=":
+		return n >= 0
+	default:
+		return n == 0
+	}
+}
+
+// isRange reports whether pattern is a version range expression rather than
+// a version string, prefix or glob pattern, i.e., whether it starts with an
+// operator or uses the .. or || syntax. Versions that merely contain
+// operator characters or spaces, such as "1.0 beta", are not ranges.
+func isRange(pattern string) bool {
+	if isGlob(pattern) {
+		return false
+	}
+	return hasAnyPrefix(strings.TrimSpace(pattern), "<>=^~") ||
+		strings.Contains(pattern, "..") ||
+		strings.Contains(pattern, "||")
+}
+
+var (
+	reRangeOperator = regexp.MustCompile(`(<=|>=|<|>|=|\^|~)\s+`)
+	reComparator    = regexp.MustCompile(`^(<=|>=|<|>|=|\^|~)?(.+)$`)
+	rePartialSemver = regexp.MustCompile(`^(\d+)(?:\.(\d+)(?:\.(\d+)(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)?)?$`)
+)
+
+// parseRange parses a version range expression. Its comparators are composed
+// of an operator (<, <=, >, >=, =) and a version string. For semver, version
+// strings may be partial, as in 1.2, and the following shorthands are
+// supported:
+//
+//	^1.2.3    >=1.2.3 <2.0.0-0 (changes that do not modify the left-most non-zero number)
+//	~1.2.3    >=1.2.3 <1.3.0-0 (patch-level changes)
+//	1.2..2.0  >=1.2.0 <2.1.0-0 (inclusive on both ends; either end may be omitted)
+//
+// Upper bounds derived from partial version strings exclude the pre-releases
+// of the bound, so that ^1.2 does not match 2.0.0-rc.1.
+func parseRange(s versionScheme, expr string) (versionRange, error) {
+	var r versionRange
+	for _, alt := range strings.Split(expr, "||") {
+		alt = strings.TrimSpace(reRangeOperator.ReplaceAllString(alt, "$1"))
+		if alt == "" {
+			return nil, fmt.Errorf("invalid version range: %s", expr)
+		}
+		var set []comparator
+		if i := strings.Index(alt, ".."); i >= 0 {
+			lo, hi := strings.TrimSpace(alt[:i]), strings.TrimSpace(alt[i+2:])
+			if lo == "" && hi == "" {
+				return nil, fmt.Errorf("invalid version range: %s", expr)
+			}
+			if lo != "" {
+				cs, err := parseComparator(s, ">=", lo)
+				if err != nil {
+					return nil, err
+				}
+				set = append(set, cs...)
+			}
+			if hi != "" {
+				cs, err := parseComparator(s, "<=", hi)
+				if err != nil {
+					return nil, err
+				}
+				set = append(set, cs...)
+			}
+			r = append(r, set)
+			continue
+		}
+		for _, field := range strings.Fields(alt) {
+			m := reComparator.FindStringSubmatch(field)
+			cs, err := parseComparator(s, m[1], m[2])
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, cs...)
+		}
+		r = append(r, set)
+	}
+	return r, nil
+}
+
+// parseComparator returns the comparators that op and ver, which may be
+// partial, translate to.
+func parseComparator(s versionScheme, op, ver string) ([]comparator, error) {
+	_, ver = splitVersion(s, ver)
+	if _, ok := unprefixed(s).(semverScheme); !ok {
+		switch {
+		case op == "^" || op == "~":
+			return nil, fmt.Errorf("invalid version range operator: %s is only supported by semver", op)
+		case !s.valid(ver):
+			return nil, fmt.Errorf("invalid version in range: %s", ver)
+		case op == "":
+			op = "="
+		}
+		return []comparator{{op, ver}}, nil
+	}
+	m := rePartialSemver.FindStringSubmatch(ver)
+	if m == nil {
+		return nil, fmt.Errorf("invalid version in range: %s", ver)
+	}
+	var (
+		n    int // the number of version numbers given
+		nums [3]int
+	)
+	for i, s := range m[1:4] {
+		if s == "" {
+			break
+		}
+		nums[i], _ = strconv.Atoi(s)
+		n++
+	}
+	var (
+		major, minor, patch = nums[0], nums[1], nums[2]
+		lower               = ver
+		upper               string // the exclusive upper bound of a partial version
+	)
+	switch n {
+	case 1:
+		lower = fmt.Sprintf("%d.0.0", major)
+		upper = fmt.Sprintf("%d.0.0-0", major+1)
+	case 2:
+		lower = fmt.Sprintf("%d.%d.0", major, minor)
+		upper = fmt.Sprintf("%d.%d.0-0", major, minor+1)
+	}
+	switch op {
+	case "^":
+		switch {
+		case major > 0 || n == 1:
+			upper = fmt.Sprintf("%d.0.0-0", major+1)
+		case minor > 0 || n == 2:
+			upper = fmt.Sprintf("0.%d.0-0", minor+1)
+		default:
+			upper = fmt.Sprintf("0.0.%d-0", patch+1)
+		}
+		return []comparator{{">=", lower}, {"<", upper}}, nil
+	case "~":
+		if n > 1 {
+			upper = fmt.Sprintf("%d.%d.0-0", major, minor+1)
+		}
+		return []comparator{{">=", lower}, {"<", upper}}, nil
+	}
+	if n == 3 {
+		if op == "" {
+			op = "="
+		}
+		return []comparator{{op, ver}}, nil
+	}
+	switch op {
+	case ">":
+		return []comparator{{">=", upper}}, nil
+	case ">=":
+		return []comparator{{">=", lower}}, nil
+	case "<":
+		return []comparator{{"<", lower + "-0"}}, nil
+	case "<=":
+		return []comparator{{"<", upper}}, nil
+	default:
+		return []comparator{{">=", lower}, {"<", upper}}, nil
+	}
+}
+
+func (r versionRange) match(s versionScheme, ver string) bool {
+	if !s.valid(ver) {
+		return false
+	}
+	for _, set := range r {
+		ok := true
+		for _, c := range set {
+			if !c.match(s, ver) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPattern reports whether pattern is a valid PATTERN argument, which is
+// only ever not the case for malformed version range expressions.
+func checkPattern(s versionScheme, pattern string) error {
+	if !isRange(pattern) {
+		return nil
+	}
+	_, err := parseRange(s, pattern)
+	return err
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVersionRange(t *testing.T) {
+	vers := []string{
+		"0.0.3",
+		"0.2.3",
+		"0.2.9",
+		"1.2.0",
+		"1.2.3",
+		"1.2.9",
+		"1.3.0",
+		"1.4.0",
+		"1.9.0",
+		"2.0.0-rc.1",
+		"2.0.0",
+		"2.0.1",
+		"3.0.0",
+	}
+	for _, test := range []struct {
+		expr string
+		exp  []string
+		err  bool
+	}{
+		{expr: ">=1.4.0 <2.0.0", exp: []string{"1.4.0", "1.9.0", "2.0.0-rc.1"}},
+		{expr: ">= 1.4.0 < 2", exp: []string{"1.4.0", "1.9.0"}},
+		{expr: ">1.4.0", exp: []string{"1.9.0", "2.0.0-rc.1", "2.0.0", "2.0.1", "3.0.0"}},
+		{expr: ">1", exp: []string{"2.0.0-rc.1", "2.0.0", "2.0.1", "3.0.0"}},
+		{expr: "<=1.2", exp: []string{"0.0.3", "0.2.3", "0.2.9", "1.2.0", "1.2.3", "1.2.9"}},
+		{expr: "<0.2", exp: []string{"0.0.3"}},
+		{expr: "=1.2", exp: []string{"1.2.0", "1.2.3", "1.2.9"}},
+		{expr: "1.4.0..2.0.0", exp: []string{"1.4.0", "1.9.0", "2.0.0-rc.1", "2.0.0"}},
+		{expr: "1.4..2.0", exp: []string{"1.4.0", "1.9.0", "2.0.0-rc.1", "2.0.0", "2.0.1"}},
+		{expr: "2.0.0..", exp: []string{"2.0.0", "2.0.1", "3.0.0"}},
+		{expr: "..0.2.3", exp: []string{"0.0.3", "0.2.3"}},
+		{expr: "^1.2", exp: []string{"1.2.0", "1.2.3", "1.2.9", "1.3.0", "1.4.0", "1.9.0"}},
+		{expr: "^1.2.3", exp: []string{"1.2.3", "1.2.9", "1.3.0", "1.4.0", "1.9.0"}},
+		{expr: "^0.2.3", exp: []string{"0.2.3", "0.2.9"}},
+		{expr: "^0.0.3", exp: []string{"0.0.3"}},
+		{expr: "~1.2.3", exp: []string{"1.2.3", "1.2.9"}},
+		{expr: "~1", exp: []string{"1.2.0", "1.2.3", "1.2.9", "1.3.0", "1.4.0", "1.9.0"}},
+		{expr: "~0.2 || >=3", exp: []string{"0.2.3", "0.2.9", "3.0.0"}},
+		{expr: ">=a.b.c", err: true},
+		{expr: "1.0.0..2.0.0 <1.5.0", err: true},
+		{expr: "..", err: true},
+		{expr: "^1 ||", err: true},
+	} {
+		t.Run(test.expr, func(t *testing.T) {
+			s := semverScheme{}
+			r, err := parseRange(s, test.expr)
+			if test.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, ver := range vers {
+				if r.match(s, ver) {
+					got = append(got, ver)
+				}
+			}
+			if !reflect.DeepEqual(got, test.exp) {
+				t.Errorf("expected %v, got %v", test.exp, got)
+			}
+		})
+	}
+}
+
+func TestIsRange(t *testing.T) {
+	for pattern, exp := range map[string]bool{
+		">=1.4.0 <2.0.0": true,
+		" ^1.2":          true,
+		"~1.2.3":         true,
+		"=1.2":           true,
+		"1.4.0..2.0.0":   true,
+		"2.0.0..":        true,
+		"1.0.0 || 2.0.0": true,
+		"1.2.3":          false,
+		"1.2":            false,
+		"1.*":            false,
+		"1.0 beta":       false,
+		"build=5":        false,
+		"v1.0~rc1":       false,
+	} {
+		if got := isRange(pattern); got != exp {
+			t.Errorf("%q: expected %t, got %t", pattern, exp, got)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+const maxMentionStats = 10
+
+type changelogStats struct {
+	releases releases
+	labels   changeSet     // all changes, grouped by label
+	gaps     []releaseGap  // between consecutive dated releases, oldest first
+	waits    []float64     // the number of days changes sat in Unreleased
+	mentions []mentionStat // ordered by count
+}
+
+type releaseGap struct {
+	from, to *release
+	days     float64
+}
+
+type mentionStat struct {
+	name  string
+	count int
+}
+
+// computeStats computes the statistics of log, whose changes start on the
+// lines recorded in items. If added holds the times at which each line was
+// added, as reported by git blame, it also computes how long changes sat in
+// the Unreleased section before being released.
+func computeStats(log *changelog, cfg *config, items []changeItem, added []time.Time) *changelogStats {
+	st := &changelogStats{releases: log.releases}
+
+	all := new(release)
+	mentions := make(map[string]int)
+	countMentions := func(s string) {
+		for _, m := range reMention.FindAllStringSubmatch(s, -1) {
+			name := m[1]
+			if name == "" {
+				name = m[3]
+			}
+			mentions[name]++
+		}
+	}
+	var dated releases
+	for _, rel := range log.releases {
+		countMentions(rel.note)
+		for _, g := range rel.changes {
+			for _, ch := range g.changes {
+				all.pushChange(g.label, ch)
+				countMentions(ch)
+			}
+		}
+		if !rel.date.IsZero() {
+			dated = append(dated, rel)
+		}
+	}
+	st.labels = all.changes.sorted(cfg.Changes.Labels)
+
+	for name, n := range mentions {
+		st.mentions = append(st.mentions, mentionStat{name, n})
+	}
+	sort.Slice(st.mentions, func(i, j int) bool {
+		a, b := st.mentions[i], st.mentions[j]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		return a.name < b.name
+	})
+	if len(st.mentions) > maxMentionStats {
+		st.mentions = st.mentions[:maxMentionStats]
+	}
+
+	sort.SliceStable(dated, func(i, j int) bool {
+		return dated[i].date.Before(dated[j].date)
+	})
+	for i := 1; i < len(dated); i++ {
+		st.gaps = append(st.gaps, releaseGap{
+			from: dated[i-1],
+			to:   dated[i],
+			days: days(dated[i].date.Sub(dated[i-1].date)),
+		})
+	}
+
+	for _, item := range items {
+		rel := item.rel
+		if rel.date.IsZero() || item.line > len(added) {
+			continue
+		}
+		// Changes that were added after their release, e.g., when a changelog
+		// is first committed, have no meaningful wait time.
+		if d := rel.date.Sub(truncateDay(added[item.line-1])); d >= 0 {
+			st.waits = append(st.waits, days(d))
+		}
+	}
+	return st
+}
+
+func days(d time.Duration) float64 {
+	return d.Hours() / 24
+}
+
+// truncateDay returns the start of the day of t in UTC, which is the time zone
+// of release dates.
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func (st *changelogStats) changeCount() (n int) {
+	for _, g := range st.labels {
+		n += len(g.changes)
+	}
+	return
+}
+
+func (st *changelogStats) cadence() []float64 {
+	res := make([]float64, len(st.gaps))
+	for i, g := range st.gaps {
+		res[i] = g.days
+	}
+	return res
+}
+
+func (st *changelogStats) longestGap() (res releaseGap, ok bool) {
+	for _, g := range st.gaps {
+		if !ok || g.days > res.days {
+			res, ok = g, true
+		}
+	}
+	return
+}
+
+func mean(vals []float64) float64 {
+	var sum float64
+	for _, v := range vals {
+		sum += v
+	}
+	return sum / float64(len(vals))
+}
+
+func median(vals []float64) float64 {
+	s := make([]float64, len(vals))
+	copy(s, vals)
+	sort.Float64s(s)
+	if n := len(s); n%2 == 0 {
+		return (s[n/2-1] + s[n/2]) / 2
+	}
+	return s[len(s)/2]
+}
+
+// round rounds days to a single decimal.
+func round(days float64) float64 {
+	return math.Round(days*10) / 10
+}
+
+func formatDays(days float64) string {
+	unit := "days"
+	if round(days) == 1 {
+		unit = "day"
+	}
+	return fmt.Sprintf("%g %s", round(days), unit)
+}
+
+// write writes st as a set of tables.
+func (st *changelogStats) write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Release\tDate\tChanges\n")
+	for _, rel := range st.releases {
+		var date string
+		if !rel.date.IsZero() {
+			date = rel.date.Format(iso8601)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", rel, date, rel.changeCount())
+	}
+	fmt.Fprintf(tw, "\nLabel\tChanges\n")
+	for _, g := range st.labels {
+		label := g.label
+		if label == keyUnlabeled {
+			label = "(unlabeled)"
+		}
+		fmt.Fprintf(tw, "%s\t%d\n", label, len(g.changes))
+	}
+	fmt.Fprintf(tw, "Total\t%d\n", st.changeCount())
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw)
+	describe := func(vals []float64) string {
+		if len(vals) == 0 {
+			return "n/a"
+		}
+		return fmt.Sprintf("mean %s, median %s", formatDays(mean(vals)), formatDays(median(vals)))
+	}
+	fmt.Fprintf(tw, "Cadence:\t%s\n", describe(st.cadence()))
+	if g, ok := st.longestGap(); ok {
+		fmt.Fprintf(tw, "Longest gap:\t%s (%s to %s)\n", formatDays(g.days), g.from, g.to)
+	} else {
+		fmt.Fprintf(tw, "Longest gap:\tn/a\n")
+	}
+	fmt.Fprintf(tw, "Time in Unreleased:\t%s\n", describe(st.waits))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(st.mentions) == 0 {
+		return nil
+	}
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\nMention\tCount\n")
+	for _, m := range st.mentions {
+		fmt.Fprintf(tw, "%s\t%d\n", m.name, m.count)
+	}
+	return tw.Flush()
+}
+
+func (st *changelogStats) doc() statsDoc {
+	doc := statsDoc{
+		Releases: releaseSummaryDocs(st.releases),
+		Labels:   []labelStatsDoc{},
+		Mentions: []mentionStatsDoc{},
+	}
+	for _, g := range st.labels {
+		doc.Labels = append(doc.Labels, labelStatsDoc{
+			Label:   g.label,
+			Changes: len(g.changes),
+		})
+	}
+	if vals := st.cadence(); len(vals) > 0 {
+		doc.Cadence = &durationStatsDoc{
+			MeanDays:   round(mean(vals)),
+			MedianDays: round(median(vals)),
+		}
+	}
+	if g, ok := st.longestGap(); ok {
+		doc.LongestGap = &gapDoc{
+			From: g.from.version,
+			To:   g.to.version,
+			Days: round(g.days),
+		}
+	}
+	if len(st.waits) > 0 {
+		doc.UnreleasedTime = &durationStatsDoc{
+			MeanDays:   round(mean(st.waits)),
+			MedianDays: round(median(st.waits)),
+		}
+	}
+	for _, m := range st.mentions {
+		doc.Mentions = append(doc.Mentions, mentionStatsDoc{
+			Name:  m.name,
+			Count: m.count,
+		})
+	}
+	return doc
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	templateSuffix   = ".tmpl"
+	localTemplateDir = ".kc/templates"
+)
+
+// templateDirs returns the directories that user-defined templates of kind
+// ("changelog" or "config") are loaded from, in ascending order of
+// precedence: the user's, i.e., $XDG_CONFIG_HOME/kc/templates/KIND, and the
+// repository's, i.e., .kc/templates/KIND, which is searched for in the current
+// directory and its ancestors up to the project root.
+func templateDirs(kind string) []string {
+	var dirs []string
+	base := os.Getenv("XDG_CONFIG_HOME")
+	if base == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			base = filepath.Join(home, ".config")
+		}
+	}
+	if base != "" {
+		dirs = append(dirs, filepath.Join(base, "kc", "templates", kind))
+	}
+	if dir, err := findLocalTemplateDir("."); err == nil {
+		dirs = append(dirs, filepath.Join(dir, kind))
+	}
+	return dirs
+}
+
+// findLocalTemplateDir searches for the repository's template directory in dir
+// and its ancestors, up to the project root, i.e., the nearest directory that
+// holds a changelog or a git repository. If there is no project root, only dir
+// is searched.
+func findLocalTemplateDir(dir string) (string, error) {
+	root, ok := projectRoot(dir)
+	if !ok {
+		root = dir
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, localTemplateDir)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return path, nil
+		}
+		if abs, err := filepath.Abs(dir); err != nil || abs == root {
+			return "", errFileNotFound
+		}
+		up, ok := relativeParentDir(dir)
+		if !ok {
+			return "", errFileNotFound
+		}
+		dir = up
+	}
+}
+
+// projectRoot returns the nearest of dir and its ancestors that holds
+// a changelog or a git repository.
+func projectRoot(dir string) (string, bool) {
+	if pathExists(filepath.Join(dir, ".git")) {
+		return dir, true
+	}
+	for _, d := range []string{dir, filepath.Join(dir, "doc"), filepath.Join(dir, "docs")} {
+		info, _ := ioutil.ReadDir(d)
+		for _, f := range info {
+			if !f.IsDir() && reChangelogNames.MatchString(f.Name()) {
+				return dir, true
+			}
+		}
+	}
+	up, ok := relativeParentDir(dir)
+	if !ok {
+		return "", false
+	}
+	return projectRoot(up)
+}
+
+// loadTemplates returns builtin merged with the *.tmpl files found in dirs,
+// which are named after the files, in lowercase. Files found in later
+// directories take precedence, including over the built-in templates.
+func loadTemplates(builtin templates, dirs ...string) (templates, error) {
+	res := make(templates, len(builtin))
+	for name, tmpl := range builtin {
+		res[name] = tmpl
+	}
+	for _, dir := range dirs {
+		info, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range info {
+			if f.IsDir() || filepath.Ext(f.Name()) != templateSuffix {
+				continue
+			}
+			data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+			if err != nil {
+				return nil, err
+			}
+			name := strings.ToLower(strings.TrimSuffix(f.Name(), templateSuffix))
+			res[name] = string(data)
+		}
+	}
+	return res, nil
+}
+
+// templates returns the built-in templates of kind merged with the
+// user-defined ones.
+func (inv *invocation) templates(kind string) templates {
+	builtin := changelogTemplates
+	if kind == "config" {
+		builtin = configTemplates
+	}
+	tmpls, err := loadTemplates(builtin, templateDirs(kind)...)
+	if err != nil {
+		panic(ioError{err})
+	}
+	return tmpls
+}
+
+// templateFuncs returns the functions available to changelog and config
+// templates.
+func (inv *invocation) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"prompt": inv.promptChoice,
+		"env":    os.Getenv,
+		"gitRemote": func(name ...string) string {
+			remote := "origin"
+			if len(name) > 0 {
+				remote = name[0]
+			}
+			out, err := git(".", "remote", "get-url", remote)
+			if err != nil {
+				return ""
+			}
+			return gitRepository(strings.TrimSpace(out))
+		},
+		"date": func(layout ...string) string {
+			if len(layout) > 0 {
+				return time.Now().Format(layout[0])
+			}
+			return time.Now().Format(iso8601)
+		},
+	}
+}
+
+// gitRepository returns the repository path of the remote URL, e.g.,
+// "user/repository" for both https://github.com/user/repository.git and
+// git@github.com:user/repository.git.
+func gitRepository(remote string) string {
+	path := remote
+	if strings.Contains(remote, "://") {
+		if u, err := url.Parse(remote); err == nil {
+			path = u.Path
+		}
+	} else if i := strings.Index(remote, ":"); i >= 0 {
+		path = remote[i+1:]
+	}
+	return strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+}
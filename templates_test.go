@@ -0,0 +1,69 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitRepository(t *testing.T) {
+	for remote, exp := range map[string]string{
+		"https://github.com/user/repository.git":        "user/repository",
+		"https://github.com/user/repository":            "user/repository",
+		"git@github.com:user/repository.git":            "user/repository",
+		"ssh://git@gitlab.com/group/sub/repository.git": "group/sub/repository",
+		"gitlab.com:group/repository/":                  "group/repository",
+	} {
+		if got := gitRepository(remote); got != exp {
+			t.Errorf("%q: expected %q, got %q", remote, exp, got)
+		}
+	}
+}
+
+func TestFindLocalTemplateDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer cd(t, cd(t, dir))
+
+	for _, path := range []string{
+		".kc/templates",
+		"project/.kc/templates",
+		"project/sub/dir",
+		"other/sub",
+	} {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, path := range []string{"project/CHANGELOG.md", "other/sub/CHANGELOG.md"} {
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, test := range []struct {
+		dir, exp string
+	}{
+		{dir: "project/sub/dir", exp: "project/.kc/templates"},
+		{dir: "project", exp: "project/.kc/templates"},
+		{dir: ".", exp: ".kc/templates"},
+		// The search stops at the project root, so the template directory of
+		// an ancestor is not found.
+		{dir: "other/sub"},
+	} {
+		got, err := findLocalTemplateDir(test.dir)
+		if err == nil {
+			got, _ = filepath.Abs(got)
+			got, _ = filepath.Rel(dir, got)
+		}
+		if test.exp == "" && err == nil {
+			t.Errorf("%s: expected no template directory, got %s", test.dir, got)
+		}
+		if test.exp != "" && got != test.exp {
+			t.Errorf("%s: expected %s, got %s (%v)", test.dir, test.exp, got, err)
+		}
+	}
+}
@@ -44,6 +44,82 @@ func write(path string, mode int, fn func(*os.File) error) error {
 	return fn(f)
 }
 
+const backupSuffix = ".kc.bak"
+
+// writeAtomic writes to a temporary file in the directory of path and, once fn
+// succeeds, renames it over path, so that path is never left truncated. The
+// permissions of an existing file are preserved. If backup is set, the
+// previous contents of path are kept at path+backupSuffix.
+func writeAtomic(path string, backup bool, fn func(*os.File) error) (err error) {
+	// Replace the target of a symlink rather than the symlink itself.
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	mode := os.FileMode(0644)
+	info, statErr := os.Stat(path)
+	if statErr == nil {
+		mode = info.Mode().Perm()
+	}
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := ioutil.TempFile(dir, fmt.Sprintf(".%s.*.tmp", name))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+	if err = fn(f); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(f.Name(), mode); err != nil {
+		return err
+	}
+	if backup && statErr == nil {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		err = writeAtomic(path+backupSuffix, false, func(f *os.File) error {
+			_, err := f.Write(data)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err = os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir flushes the entries of dir to disk, so that a rename within dir
+// survives a crash. Directories cannot be synced on Windows, where this is
+// a no-op.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
 func hasAnyPrefix(s string, xyz string) bool {
 	if len(s) == 0 {
 		return false
@@ -56,6 +132,15 @@ func hasAnyPrefix(s string, xyz string) bool {
 	return false
 }
 
+func contains(vals []string, s string) bool {
+	for _, val := range vals {
+		if val == s {
+			return true
+		}
+	}
+	return false
+}
+
 func pluralize(word string, n int) string {
 	if n == 1 {
 		return word
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// version is a Semantic Versioning 2.0 version string.
+type version struct {
+	major, minor, patch int
+	pre                 []string // dot-separated pre-release identifiers
+	build               string   // build metadata; ignored when comparing
+}
+
+var reSemver = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+([0-9A-Za-z.-]+))?$`)
+
+func parseVersion(s string) (v version, ok bool) {
+	m := reSemver.FindStringSubmatch(s)
+	if m == nil {
+		return v, false
+	}
+	v.major, _ = strconv.Atoi(m[1])
+	v.minor, _ = strconv.Atoi(m[2])
+	v.patch, _ = strconv.Atoi(m[3])
+	if m[4] != "" {
+		v.pre = strings.Split(m[4], ".")
+	}
+	v.build = m[5]
+	return v, true
+}
+
+func isVersion(s string) bool {
+	_, ok := parseVersion(s)
+	return ok
+}
+
+// validVersion reports whether s strictly adheres to the semver grammar, which
+// parseVersion is lenient about: numeric identifiers may not have leading
+// zeros and identifiers may not be empty.
+func validVersion(s string) bool {
+	m := reSemver.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	ids := append([]string(nil), m[1:4]...)
+	if m[4] != "" {
+		ids = append(ids, strings.Split(m[4], ".")...)
+	}
+	for _, id := range ids {
+		if id == "" || isNumeric(id) && len(id) > 1 && id[0] == '0' {
+			return false
+		}
+	}
+	if m[5] != "" {
+		for _, id := range strings.Split(m[5], ".") {
+			if id == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (v version) core() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+func (v version) prerelease() bool {
+	return len(v.pre) > 0
+}
+
+func (v version) String() string {
+	s := v.core()
+	if v.prerelease() {
+		s += "-" + strings.Join(v.pre, ".")
+	}
+	if v.build != "" {
+		s += "+" + v.build
+	}
+	return s
+}
+
+// compare returns an integer comparing the precedence of v and w. The result
+// is 0 if v == w, -1 if v < w, and +1 if v > w.
+func (v version) compare(w version) int {
+	for _, n := range [][2]int{
+		{v.major, w.major},
+		{v.minor, w.minor},
+		{v.patch, w.patch},
+	} {
+		if c := compareInts(n[0], n[1]); c != 0 {
+			return c
+		}
+	}
+	// A pre-release version has lower precedence than a normal version.
+	switch {
+	case !v.prerelease() && !w.prerelease():
+		return 0
+	case !v.prerelease():
+		return 1
+	case !w.prerelease():
+		return -1
+	}
+	for i := 0; i < len(v.pre) && i < len(w.pre); i++ {
+		if c := compareIdentifiers(v.pre[i], w.pre[i]); c != 0 {
+			return c
+		}
+	}
+	// A larger set of pre-release fields has a higher precedence than
+	// a smaller set, if all of the preceding identifiers are equal.
+	return compareInts(len(v.pre), len(w.pre))
+}
+
+// compareIdentifiers compares two pre-release identifiers. Numeric identifiers
+// are compared numerically and always have lower precedence than alphanumeric
+// identifiers, which are compared lexically.
+func compareIdentifiers(a, b string) int {
+	na, nb := isNumeric(a), isNumeric(b)
+	switch {
+	case na && nb:
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return compareInts(x, y)
+	case na:
+		return -1
+	case nb:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Version bump types. The pre-release bump types are also used as the first
+// identifier of the resulting pre-release.
+const (
+	bumpMajor      = "major"
+	bumpMinor      = "minor"
+	bumpPatch      = "patch"
+	bumpPrerelease = "prerelease"
+	bumpAlpha      = "alpha"
+	bumpBeta       = "beta"
+	bumpRC         = "rc"
+)
+
+var bumpTypes = []string{
+	bumpMajor,
+	bumpMinor,
+	bumpPatch,
+	bumpPrerelease,
+	bumpAlpha,
+	bumpBeta,
+	bumpRC,
+}
+
+// matchBumpType returns the bump type (or auto) that typ is a prefix of. The
+// core types take precedence over the pre-release ones, so that "p" remains
+// short for patch rather than being ambiguous with prerelease.
+func matchBumpType(typ string) (string, error) {
+	p := prefix(typ)
+	if ms := p.match([]string{bumpAuto, bumpMajor, bumpMinor, bumpPatch}); len(ms) == 1 {
+		return ms[0], nil
+	}
+	return p.matchAs(append([]string{bumpAuto}, bumpTypes...), "release type")
+}
+
+// bump returns the version that follows v according to typ, which must be one
+// of bumpTypes.
+//
+// Bumping a pre-release by major, minor or patch releases it if v is
+// already a pre-release of the version that would otherwise result, i.e.,
+// 2.0.0-rc.1 becomes 2.0.0 when bumped by major. Bumping by a pre-release
+// type increments the pre-release counter if v is a pre-release of the same
+// type, or starts a new pre-release otherwise.
+func (v version) bump(typ string) (version, error) {
+	next := version{major: v.major, minor: v.minor, patch: v.patch}
+	switch typ {
+	case bumpMajor:
+		if !v.prerelease() || v.minor != 0 || v.patch != 0 {
+			next.major++
+			next.minor = 0
+			next.patch = 0
+		}
+	case bumpMinor:
+		if !v.prerelease() || v.patch != 0 {
+			next.minor++
+			next.patch = 0
+		}
+	case bumpPatch:
+		if !v.prerelease() {
+			next.patch++
+		}
+	case bumpPrerelease:
+		if !v.prerelease() {
+			return v.bump(bumpRC)
+		}
+		next.pre = incrementPrerelease(v.pre)
+	case bumpAlpha, bumpBeta, bumpRC:
+		switch {
+		case !v.prerelease():
+			next.patch++
+			next.pre = []string{typ, "1"}
+		case v.pre[0] == typ:
+			next.pre = incrementPrerelease(v.pre)
+		default:
+			next.pre = []string{typ, "1"}
+		}
+	default:
+		panicf("version.bump: unexpected bump type: %s", typ)
+	}
+	if next.compare(v) <= 0 {
+		return next, fmt.Errorf("%s would not succeed %s", next, v)
+	}
+	return next, nil
+}
+
+// bumpAuto instructs kc to infer the bump type from the unreleased changes.
+const bumpAuto = "auto"
+
+var defaultReleaseBumps = map[string]string{
+	"Removed":    bumpMajor,
+	"Added":      bumpMinor,
+	"Deprecated": bumpMinor,
+}
+
+const defaultBreakingMarker = "BREAKING"
+
+// breakingMarker returns the release.breaking marker, which denotes breaking
+// changes.
+func (c *config) breakingMarker() string {
+	if c.Release.Breaking == "" {
+		return defaultBreakingMarker
+	}
+	return c.Release.Breaking
+}
+
+// mergeBumps returns a merged with b, whose labels replace those of a
+// regardless of case.
+func mergeBumps(a, b map[string]string) map[string]string {
+	res := make(map[string]string, len(a)+len(b))
+	for label, bump := range a {
+		res[label] = bump
+	}
+	for label, bump := range b {
+		for l := range res {
+			if strings.EqualFold(l, label) {
+				delete(res, l)
+			}
+		}
+		res[label] = bump
+	}
+	return res
+}
+
+// isBreaking reports whether the change ch contains marker as a whole word,
+// e.g., "BREAKING: ..." or "... (**BREAKING**)", but not "BREAKING_CHANGES".
+func isBreaking(ch, marker string) bool {
+	isWord := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-'
+	}
+	if marker == "" {
+		return false
+	}
+	first, _ := utf8.DecodeRuneInString(marker)
+	last, _ := utf8.DecodeLastRuneInString(marker)
+	for i := 0; ; {
+		j := strings.Index(ch[i:], marker)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(marker)
+		before, _ := utf8.DecodeLastRuneInString(ch[:start])
+		after, _ := utf8.DecodeRuneInString(ch[end:])
+		if (start == 0 || !isWord(first) || !isWord(before)) &&
+			(end == len(ch) || !isWord(last) || !isWord(after)) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+// bumpType infers the bump type for rel from its change labels, according to
+// the release.bumps table. Labels that are not found in the table bump the
+// patch number, except for the label of breaking commits (see commitLabel),
+// which bumps the major number. Changes that contain the release.breaking
+// marker always bump the major number.
+func (c *config) bumpType(rel *release) (string, error) {
+	var (
+		bumps  = make(map[string]string)
+		marker = c.breakingMarker()
+		typ    = bumpPatch
+		rank   = map[string]int{bumpPatch: 0, bumpMinor: 1, bumpMajor: 2}
+	)
+	if label, ok := c.breakingLabel(); ok {
+		bumps[label] = bumpMajor
+	}
+	if c.Release.Bumps == nil {
+		bumps = mergeBumps(bumps, defaultReleaseBumps)
+	} else {
+		bumps = mergeBumps(bumps, c.Release.Bumps)
+	}
+	for _, g := range rel.changes {
+		for _, ch := range g.changes {
+			if isBreaking(ch, marker) {
+				return bumpMajor, nil
+			}
+		}
+		if len(g.changes) == 0 {
+			continue
+		}
+		for label, bump := range bumps {
+			if !strings.EqualFold(label, g.label) {
+				continue
+			}
+			if _, ok := rank[bump]; !ok {
+				return "", fmt.Errorf("invalid bump type for label %q: %q", label, bump)
+			}
+			if rank[bump] > rank[typ] {
+				typ = bump
+			}
+		}
+	}
+	return typ, nil
+}
+
+// incrementPrerelease increments the last numeric identifier in ids or appends
+// a numeric identifier if the last one is not numeric.
+func incrementPrerelease(ids []string) []string {
+	res := make([]string, len(ids))
+	copy(res, ids)
+	last := len(res) - 1
+	if !isNumeric(res[last]) {
+		return append(res, "1")
+	}
+	n, _ := strconv.Atoi(res[last])
+	res[last] = strconv.Itoa(n + 1)
+	return res
+}
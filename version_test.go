@@ -0,0 +1,118 @@
+package main
+
+import "testing"
+
+func TestVersionCompare(t *testing.T) {
+	// Sorted in ascending order of precedence.
+	vers := []string{
+		"0.9.9",
+		"1.0.0-0.3.7",
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+	for i, a := range vers {
+		va, ok := parseVersion(a)
+		if !ok {
+			t.Fatalf("%s: invalid version", a)
+		}
+		for j, b := range vers {
+			vb, _ := parseVersion(b)
+			if got, exp := va.compare(vb), compareInts(i, j); got != exp {
+				t.Errorf("%s <=> %s: expected %d, got %d", a, b, exp, got)
+			}
+		}
+	}
+	a, _ := parseVersion("1.0.0+build.1")
+	b, _ := parseVersion("1.0.0+build.2")
+	if c := a.compare(b); c != 0 {
+		t.Errorf("build metadata must be ignored, got %d", c)
+	}
+}
+
+func TestValidVersion(t *testing.T) {
+	for ver, exp := range map[string]bool{
+		"1.0.0":                true,
+		"1.0.0-rc.1":           true,
+		"1.0.0-0.3.7+build.01": true,
+		"1.0.0-rc.1+a.b":       true,
+		"01.0.0":               false,
+		"1.0.0-rc.01":          false,
+		"1.0.0-rc..1":          false,
+		"1.0.0+a..b":           false,
+		"1.0.0-rc.1+a..b":      false,
+		"1.0.0-rc+":            false,
+		"1.0.0beta":            false,
+	} {
+		if got := validVersion(ver); got != exp {
+			t.Errorf("%s: expected %t, got %t", ver, exp, got)
+		}
+	}
+}
+
+func TestVersionBump(t *testing.T) {
+	for _, test := range []struct {
+		ver, typ, exp string
+		err           bool
+	}{
+		{ver: "0.0.0", typ: "patch", exp: "0.0.1"},
+		{ver: "1.2.3", typ: "minor", exp: "1.3.0"},
+		{ver: "1.2.3", typ: "major", exp: "2.0.0"},
+		{ver: "1.2.3+build", typ: "patch", exp: "1.2.4"},
+		{ver: "1.2.3", typ: "rc", exp: "1.2.4-rc.1"},
+		{ver: "1.2.3", typ: "prerelease", exp: "1.2.4-rc.1"},
+		{ver: "1.2.4-rc.1", typ: "rc", exp: "1.2.4-rc.2"},
+		{ver: "1.2.4-rc.9", typ: "prerelease", exp: "1.2.4-rc.10"},
+		{ver: "1.2.4-alpha", typ: "prerelease", exp: "1.2.4-alpha.1"},
+		{ver: "1.2.4-alpha.3", typ: "beta", exp: "1.2.4-beta.1"},
+		{ver: "1.2.4-rc.1", typ: "alpha", err: true},
+		{ver: "1.2.4-rc.1", typ: "patch", exp: "1.2.4"},
+		{ver: "1.3.0-rc.1", typ: "patch", exp: "1.3.0"},
+		{ver: "1.3.0-rc.1", typ: "minor", exp: "1.3.0"},
+		{ver: "1.3.1-rc.1", typ: "minor", exp: "1.4.0"},
+		{ver: "2.0.0-rc.1", typ: "major", exp: "2.0.0"},
+		{ver: "2.1.0-rc.1", typ: "major", exp: "3.0.0"},
+	} {
+		v, _ := parseVersion(test.ver)
+		next, err := v.bump(test.typ)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("%s (%s): expected error, got %s", test.ver, test.typ, next)
+		case !test.err && err != nil:
+			t.Errorf("%s (%s): %s", test.ver, test.typ, err)
+		case !test.err && next.String() != test.exp:
+			t.Errorf("%s (%s): expected %s, got %s", test.ver, test.typ, test.exp, next)
+		}
+	}
+}
+
+func TestIsBreaking(t *testing.T) {
+	for _, test := range []struct {
+		ch, marker string
+		exp        bool
+	}{
+		{ch: "BREAKING: drop the old API", marker: "BREAKING", exp: true},
+		{ch: "**BREAKING** drop the old API", marker: "BREAKING", exp: true},
+		{ch: "drop the old API (BREAKING)", marker: "BREAKING", exp: true},
+		{ch: "BREAKING", marker: "BREAKING", exp: true},
+		{ch: "a (breaking)", marker: "(breaking)", exp: true},
+		{ch: "(breaking)a", marker: "(breaking)", exp: true},
+		{ch: "Read BREAKING_CHANGES_ENV", marker: "BREAKING", exp: false},
+		{ch: "BREAKINGS", marker: "BREAKING", exp: false},
+		{ch: "Fix a non-BREAKING bug", marker: "BREAKING", exp: false},
+		{ch: "Fix breaking of long lines", marker: "BREAKING", exp: false},
+		{ch: "BREAKING_X, then BREAKING", marker: "BREAKING", exp: true},
+	} {
+		if got := isBreaking(test.ch, test.marker); got != test.exp {
+			t.Errorf("%q (%q): expected %t, got %t", test.ch, test.marker, test.exp, got)
+		}
+	}
+}
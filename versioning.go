@@ -0,0 +1,373 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Versioning schemes.
+const (
+	schemeSemver = "semver"
+	schemeCalver = "calver"
+)
+
+var schemes = []string{schemeSemver, schemeCalver}
+
+// versionScheme determines how version strings are recognized and ordered.
+type versionScheme interface {
+	fmt.Stringer
+	// valid reports whether ver is a complete version string. This is
+	// lenient, in that it accepts minor deviations from the scheme that
+	// wellFormed does not.
+	valid(ver string) bool
+	wellFormed(ver string) bool
+	// compare returns an integer comparing the precedence of a and b, which
+	// must be valid. The result is 0 if a == b, -1 if a < b, and +1 if a > b.
+	compare(a, b string) int
+	// pattern returns a regular expression that matches version strings in
+	// release headings.
+	pattern() string
+}
+
+// initVersioning sets up the versioning scheme specified by the versioning
+// table.
+func (c *config) initVersioning() error {
+	scheme := c.Versioning.Scheme
+	if scheme == "" {
+		scheme = schemeSemver
+	}
+	switch scheme {
+	case schemeSemver:
+		if c.Versioning.Format != "" {
+			return errors.New("versioning format is only supported by the calver scheme")
+		}
+		c.versioning = semverScheme{}
+	case schemeCalver:
+		format := c.Versioning.Format
+		if format == "" {
+			format = defaultCalverFormat
+		}
+		f, err := parseCalverFormat(format)
+		if err != nil {
+			return err
+		}
+		c.versioning = f
+	default:
+		return fmt.Errorf("no such versioning scheme: %s, try: %s", scheme, strings.Join(schemes, " | "))
+	}
+	if p := c.Versioning.Prefix; p != "" {
+		c.versioning = prefixedScheme{c.versioning, p}
+	}
+	return nil
+}
+
+const defaultTagTemplate = "{PREFIX}{VERSION}"
+
+// tag returns the VCS tag of the release versioned ver according to the
+// versioning.tag template. The version prefix is optional in ver.
+func (c *config) tag(ver string) string {
+	tmpl := c.Versioning.Tag
+	if tmpl == "" {
+		tmpl = defaultTagTemplate
+	}
+	p := c.Versioning.Prefix
+	return strings.NewReplacer(
+		"{PREFIX}", p,
+		"{VERSION}", strings.TrimPrefix(ver, p),
+	).Replace(tmpl)
+}
+
+func (c *config) scheme() versionScheme {
+	if c == nil || c.versioning == nil {
+		return semverScheme{}
+	}
+	return c.versioning
+}
+
+// matchVersions is like matchPattern, except that a pattern that is
+// a complete version string only matches versions of equal precedence, and
+// a pattern that is a version range matches the versions within range.
+func matchVersions(s versionScheme, vals []string, pattern string) (res []string) {
+	match := func(val string) bool {
+		return s.valid(val) && s.compare(val, pattern) == 0
+	}
+	switch {
+	case s.valid(pattern):
+	case isRange(pattern):
+		r, err := parseRange(s, pattern)
+		if err != nil {
+			return nil
+		}
+		match = func(val string) bool {
+			return r.match(s, val)
+		}
+	default:
+		return matchPattern(vals, pattern)
+	}
+	for _, val := range vals {
+		if match(val) {
+			res = append(res, val)
+		}
+	}
+	return
+}
+
+// prefixedScheme wraps a scheme, whose version strings may be prefixed, as
+// in v1.2.3.
+type prefixedScheme struct {
+	versionScheme
+	prefix string
+}
+
+func (s prefixedScheme) trim(ver string) string {
+	return strings.TrimPrefix(ver, s.prefix)
+}
+
+func (s prefixedScheme) valid(ver string) bool {
+	return s.versionScheme.valid(s.trim(ver))
+}
+
+func (s prefixedScheme) wellFormed(ver string) bool {
+	return s.versionScheme.wellFormed(s.trim(ver))
+}
+
+func (s prefixedScheme) compare(a, b string) int {
+	return s.versionScheme.compare(s.trim(a), s.trim(b))
+}
+
+func (s prefixedScheme) pattern() string {
+	return "(?:" + regexp.QuoteMeta(s.prefix) + ")?" + s.versionScheme.pattern()
+}
+
+// splitVersion splits ver into the version prefix of s, if ver has one, and
+// the remaining version string.
+func splitVersion(s versionScheme, ver string) (prefix, rest string) {
+	p, ok := s.(prefixedScheme)
+	if !ok || !strings.HasPrefix(ver, p.prefix) {
+		return "", ver
+	}
+	return p.prefix, ver[len(p.prefix):]
+}
+
+func unprefixed(s versionScheme) versionScheme {
+	if p, ok := s.(prefixedScheme); ok {
+		return p.versionScheme
+	}
+	return s
+}
+
+type semverScheme struct{}
+
+func (semverScheme) String() string {
+	return schemeSemver
+}
+
+func (semverScheme) valid(ver string) bool {
+	return isVersion(ver)
+}
+
+func (semverScheme) wellFormed(ver string) bool {
+	return validVersion(ver)
+}
+
+func (semverScheme) compare(a, b string) int {
+	va, _ := parseVersion(a)
+	vb, _ := parseVersion(b)
+	return va.compare(vb)
+}
+
+func (semverScheme) pattern() string {
+	return `\d+\.\d+\.\d+\S*?`
+}
+
+// calverFormat is a Calendar Versioning (https://calver.org/) format, such as
+// YYYY.0M.MICRO, which is composed of tokens delimited by separators.
+type calverFormat struct {
+	format string
+	tokens []string
+	re     *regexp.Regexp
+}
+
+const defaultCalverFormat = "YYYY.MM.MICRO"
+
+const calverMicro = "MICRO"
+
+// calverTokens maps format tokens onto the patterns of their values.
+var calverTokens = map[string]string{
+	"YYYY":      `\d{4}`,
+	"YY":        `[1-9]\d{0,2}|0`,
+	"0Y":        `\d{2,3}`,
+	"MM":        `1[0-2]|[1-9]`,
+	"0M":        `1[0-2]|0[1-9]`,
+	"WW":        `5[0-3]|[1-4]\d|[1-9]`,
+	"0W":        `5[0-3]|[1-4]\d|0[1-9]`,
+	"DD":        `3[01]|[12]\d|[1-9]`,
+	"0D":        `3[01]|[12]\d|0[1-9]`,
+	calverMicro: `\d+`,
+}
+
+var reCalverFormat = regexp.MustCompile(`^(YYYY|YY|0Y|MM|0M|WW|0W|DD|0D|MICRO)([._-]|$)`)
+
+func parseCalverFormat(format string) (*calverFormat, error) {
+	f := &calverFormat{format: format}
+	re := new(strings.Builder)
+	re.WriteByte('^')
+	var dated bool
+	for s := format; s != ""; {
+		m := reCalverFormat.FindStringSubmatch(s)
+		if m == nil {
+			return nil, fmt.Errorf("invalid calver format: %s", format)
+		}
+		tok, sep := m[1], m[2]
+		switch {
+		case tok == calverMicro && f.has(calverMicro):
+			return nil, fmt.Errorf("invalid calver format: %s: MICRO may only be specified once", format)
+		case tok != calverMicro:
+			dated = true
+		}
+		f.tokens = append(f.tokens, tok)
+		fmt.Fprintf(re, "(%s)%s", calverTokens[tok], regexp.QuoteMeta(sep))
+		s = s[len(m[0]):]
+		if sep != "" && s == "" {
+			return nil, fmt.Errorf("invalid calver format: %s", format)
+		}
+	}
+	if !dated {
+		return nil, fmt.Errorf("invalid calver format: %s: missing date tokens", format)
+	}
+	re.WriteByte('$')
+	f.re = regexp.MustCompile(re.String())
+	return f, nil
+}
+
+func (f *calverFormat) String() string {
+	return f.format
+}
+
+func (f *calverFormat) has(tok string) bool {
+	for _, t := range f.tokens {
+		if t == tok {
+			return true
+		}
+	}
+	return false
+}
+
+// values returns the numeric values of the tokens in ver and whether ver
+// adheres to the format. Any suffix that follows a hyphen or plus sign, such
+// as -rc.1, is ignored.
+func (f *calverFormat) values(ver string) ([]int, bool) {
+	m := f.re.FindStringSubmatch(ver)
+	if m == nil {
+		i := strings.LastIndexAny(ver, "-+")
+		if i < 0 {
+			return nil, false
+		}
+		if m = f.re.FindStringSubmatch(ver[:i]); m == nil {
+			return nil, false
+		}
+	}
+	vals := make([]int, len(f.tokens))
+	for i, s := range m[1:] {
+		vals[i], _ = strconv.Atoi(s)
+	}
+	return vals, true
+}
+
+func (f *calverFormat) valid(ver string) bool {
+	_, ok := f.values(ver)
+	return ok
+}
+
+func (f *calverFormat) wellFormed(ver string) bool {
+	return f.re.MatchString(ver)
+}
+
+func (f *calverFormat) compare(a, b string) int {
+	va, _ := f.values(a)
+	vb, _ := f.values(b)
+	for i := range va {
+		if c := compareInts(va[i], vb[i]); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+func (f *calverFormat) pattern() string {
+	return `\d+(?:[._-]\d+)*\S*?`
+}
+
+// next returns the version that follows prev, which may be empty, when
+// released at the given time. MICRO is incremented if the date tokens of prev
+// match t, and reset to 0 otherwise.
+func (f *calverFormat) next(prev string, t time.Time) (string, error) {
+	var (
+		_, week = t.ISOWeek()
+		vals    = make([]string, len(f.tokens))
+		micro   = -1
+		same    = true
+	)
+	prevVals, ok := f.values(prev)
+	if prev != "" && !ok {
+		return "", fmt.Errorf("cannot compute the version after %s: not a %s version string", prev, f)
+	}
+	for i, tok := range f.tokens {
+		var n int
+		switch tok {
+		case "YYYY", "0Y", "YY":
+			n = t.Year()
+			if tok != "YYYY" {
+				n -= 2000
+			}
+		case "MM", "0M":
+			n = int(t.Month())
+		case "WW", "0W":
+			n = week
+		case "DD", "0D":
+			n = t.Day()
+		case calverMicro:
+			micro = i
+			continue
+		}
+		if ok && prevVals[i] != n {
+			same = false
+		}
+		vals[i] = strconv.Itoa(n)
+		if tok[0] == '0' && n < 10 {
+			vals[i] = "0" + vals[i]
+		}
+	}
+	if ok && same {
+		if micro < 0 {
+			return "", fmt.Errorf("cannot compute the version after %s, which has the same date: consider adding MICRO to the %s format", prev, f)
+		}
+		vals[micro] = strconv.Itoa(prevVals[micro] + 1)
+	} else if micro >= 0 {
+		vals[micro] = "0"
+	}
+	ver := f.join(vals)
+	if ok && f.compare(ver, prev) <= 0 {
+		return "", fmt.Errorf("%s would not succeed %s", ver, prev)
+	}
+	return ver, nil
+}
+
+// join joins vals by the separators of the format.
+func (f *calverFormat) join(vals []string) string {
+	var (
+		buf = new(strings.Builder)
+		s   = f.format
+	)
+	for i := range f.tokens {
+		m := reCalverFormat.FindStringSubmatch(s)
+		buf.WriteString(vals[i])
+		buf.WriteString(m[2])
+		s = s[len(m[0]):]
+	}
+	return buf.String()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalverNext(t *testing.T) {
+	now := time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		format, prev, exp string
+		err               bool
+	}{
+		{format: "YYYY.MM.MICRO", exp: "2026.3.0"},
+		{format: "YYYY.MM.MICRO", prev: "2026.2.4", exp: "2026.3.0"},
+		{format: "YYYY.MM.MICRO", prev: "2026.3.4", exp: "2026.3.5"},
+		{format: "YYYY.0M.0D", prev: "2026.03.06", exp: "2026.03.07"},
+		{format: "YY.MM", prev: "26.2", exp: "26.3"},
+		{format: "0Y.0W", exp: "26.10"},
+		{format: "YYYY-0M-0D_MICRO", prev: "2026-03-07_0", exp: "2026-03-07_1"},
+		{format: "YY.MM", prev: "26.3", err: true},
+		{format: "YY.MM", prev: "27.1", err: true},
+		{format: "YY.MM", prev: "1.2.3", err: true},
+	} {
+		f, err := parseCalverFormat(test.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		next, err := f.next(test.prev, now)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("%s (%s): expected error, got %s", test.prev, test.format, next)
+		case !test.err && err != nil:
+			t.Errorf("%s (%s): %s", test.prev, test.format, err)
+		case !test.err && next != test.exp:
+			t.Errorf("%s (%s): expected %s, got %s", test.prev, test.format, test.exp, next)
+		}
+	}
+}
+
+func TestCalverFormat(t *testing.T) {
+	for _, format := range []string{
+		"",
+		"MICRO",
+		"YYYY.MICRO.MICRO",
+		"YYYY.",
+		"YYYY..MM",
+		"YYYY/MM",
+		"YYYYMM",
+	} {
+		if _, err := parseCalverFormat(format); err == nil {
+			t.Errorf("%q: expected error", format)
+		}
+	}
+	f, _ := parseCalverFormat("YYYY.MM.MICRO")
+	for _, test := range []struct {
+		a, b string
+		exp  int
+	}{
+		{"2026.10.1", "2026.9.12", 1},
+		{"2026.1.0", "2025.12.3", 1},
+		{"2026.1.0", "2026.1.0", 0},
+		{"2026.1.0", "2026.1.1", -1},
+	} {
+		if got := f.compare(test.a, test.b); got != test.exp {
+			t.Errorf("%s <=> %s: expected %d, got %d", test.a, test.b, test.exp, got)
+		}
+	}
+}